@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"github.com/ThreeDotsLabs/go-event-driven/common/clients"
+	"github.com/ThreeDotsLabs/go-event-driven/common/clients/dead_nation"
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+	"github.com/google/uuid"
+	"net/http"
+	"net/url"
+	"tickets/entities"
+	"time"
+)
+
+type DeadNationClient struct {
+	clients     *clients.Clients
+	gatewayAddr string
+	httpClient  *http.Client
+}
+
+func NewDeadNationClient(clients *clients.Clients, gatewayAddr string) *DeadNationClient {
+	if clients == nil {
+		panic("NewDeadNationClient: clients is nil")
+	}
+	if gatewayAddr == "" {
+		panic("NewDeadNationClient: gatewayAddr is empty")
+	}
+
+	return &DeadNationClient{
+		clients:     clients,
+		gatewayAddr: gatewayAddr,
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (c DeadNationClient) BookInDeadNation(ctx context.Context, booking entities.DeadNationBooking) error {
+	bookingID, err := uuid.Parse(booking.BookingID)
+	if err != nil {
+		return fmt.Errorf("invalid booking id %s: %w", booking.BookingID, err)
+	}
+
+	eventID, err := uuid.Parse(booking.DeadNationEventID)
+	if err != nil {
+		return fmt.Errorf("invalid dead nation event id %s: %w", booking.DeadNationEventID, err)
+	}
+
+	resp, err := c.clients.DeadNation.PostTicketBookingWithResponse(ctx, dead_nation.PostTicketBookingRequest{
+		BookingId:       bookingID,
+		CustomerAddress: booking.CustomerEmail,
+		EventId:         eventID,
+		NumberOfTickets: booking.NumberOfTickets,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to book place in dead nation: %w", err)
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("unexpected status code for POST dead-nation-api/ticket/booking: %d", resp.StatusCode())
+	}
+
+	return nil
+}
+
+// CancelInDeadNation releases the places of the booking. The generated client has no cancellation endpoint,
+// so the request is sent to the gateway directly. Bookings unknown to Dead Nation have nothing to cancel.
+func (c DeadNationClient) CancelInDeadNation(ctx context.Context, bookingID string) error {
+	id, err := uuid.Parse(bookingID)
+	if err != nil {
+		return fmt.Errorf("invalid booking id %s: %w", bookingID, err)
+	}
+
+	endpoint, err := url.JoinPath(c.gatewayAddr, "dead-nation-api", "ticket", "booking", id.String())
+	if err != nil {
+		return fmt.Errorf("failed to build dead nation url: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create dead nation request: %w", err)
+	}
+	req.Header.Set("Correlation-ID", log.CorrelationIDFromContext(ctx))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to cancel place in dead nation: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return fmt.Errorf("unexpected status code for DELETE dead-nation-api/ticket/booking/%s: %d", bookingID, resp.StatusCode)
+	}
+}
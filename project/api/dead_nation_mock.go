@@ -0,0 +1,55 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"tickets/entities"
+)
+
+type DeadNationMock struct {
+	mu               sync.Mutex
+	Bookings         []entities.DeadNationBooking
+	canceledBookings []string
+	failNextCalls    int
+}
+
+// FailNextCalls makes the next n bookings fail, as if the partner API was unavailable.
+func (d *DeadNationMock) FailNextCalls(n int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.failNextCalls = n
+}
+
+func (d *DeadNationMock) BookInDeadNation(ctx context.Context, booking entities.DeadNationBooking) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.failNextCalls > 0 {
+		d.failNextCalls--
+		return errors.New("dead nation is unavailable")
+	}
+
+	d.Bookings = append(d.Bookings, booking)
+
+	return nil
+}
+
+func (d *DeadNationMock) CancelInDeadNation(ctx context.Context, bookingID string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.canceledBookings = append(d.canceledBookings, bookingID)
+
+	return nil
+}
+
+// CanceledBookings returns the IDs of the bookings canceled so far.
+func (d *DeadNationMock) CanceledBookings() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return slices.Clone(d.canceledBookings)
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"github.com/ThreeDotsLabs/go-event-driven/common/clients"
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+	"net/http"
+)
+
+type FilesServiceClient struct {
+	clients *clients.Clients
+}
+
+func NewFilesServiceClient(clients *clients.Clients) *FilesServiceClient {
+	if clients == nil {
+		panic("NewFilesServiceClient: clients is nil")
+	}
+
+	return &FilesServiceClient{clients: clients}
+}
+
+func (c FilesServiceClient) UploadFile(ctx context.Context, fileID string, content string) error {
+	resp, err := c.clients.Files.PutFilesFileIdContentWithTextBodyWithResponse(ctx, fileID, content)
+	if err != nil {
+		return fmt.Errorf("failed to upload file %s: %w", fileID, err)
+	}
+
+	switch resp.StatusCode() {
+	case http.StatusCreated:
+		return nil
+	case http.StatusConflict:
+		// the file was uploaded by a previous delivery of the same message
+		log.FromContext(ctx).Infof("File %s already exists", fileID)
+		return nil
+	default:
+		return fmt.Errorf("unexpected status code for PUT files-api/files/%s/content: %d", fileID, resp.StatusCode())
+	}
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"context"
+	"sync"
+)
+
+type FilesMock struct {
+	mu    sync.Mutex
+	Files map[string]string
+}
+
+func (f *FilesMock) UploadFile(ctx context.Context, fileID string, content string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Files == nil {
+		f.Files = make(map[string]string)
+	}
+
+	// like the files API, an existing file is not overwritten
+	if _, ok := f.Files[fileID]; ok {
+		return nil
+	}
+
+	f.Files[fileID] = content
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type GatewayHealthChecker struct {
+	gatewayAddr string
+	httpClient  *http.Client
+}
+
+func NewGatewayHealthChecker(gatewayAddr string) *GatewayHealthChecker {
+	if gatewayAddr == "" {
+		panic("NewGatewayHealthChecker: gatewayAddr is empty")
+	}
+
+	return &GatewayHealthChecker{
+		gatewayAddr: gatewayAddr,
+		httpClient:  &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Check verifies that the gateway accepts connections and doesn't respond with a server error.
+func (c GatewayHealthChecker) Check(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.gatewayAddr, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create gateway request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("gateway is unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("unexpected gateway status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
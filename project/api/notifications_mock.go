@@ -0,0 +1,30 @@
+package api
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"tickets/entities"
+)
+
+type NotificationsMock struct {
+	mu         sync.Mutex
+	sentEmails []entities.Email
+}
+
+func (n *NotificationsMock) SendEmail(ctx context.Context, email entities.Email) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.sentEmails = append(n.sentEmails, email)
+
+	return nil
+}
+
+// SentEmails returns a copy of the emails sent so far.
+func (n *NotificationsMock) SentEmails() []entities.Email {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return slices.Clone(n.sentEmails)
+}
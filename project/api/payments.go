@@ -0,0 +1,39 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"github.com/ThreeDotsLabs/go-event-driven/common/clients"
+	"github.com/ThreeDotsLabs/go-event-driven/common/clients/payments"
+	"net/http"
+	"tickets/entities"
+)
+
+type PaymentsServiceClient struct {
+	clients *clients.Clients
+}
+
+func NewPaymentsServiceClient(clients *clients.Clients) *PaymentsServiceClient {
+	if clients == nil {
+		panic("NewPaymentsServiceClient: clients is nil")
+	}
+
+	return &PaymentsServiceClient{clients: clients}
+}
+
+func (c PaymentsServiceClient) RefundPayment(ctx context.Context, refund entities.PaymentRefund) error {
+	resp, err := c.clients.Payments.PutRefundsWithResponse(ctx, payments.PaymentRefundRequest{
+		DeduplicationId:  &refund.IdempotencyKey,
+		PaymentReference: refund.TicketID,
+		Reason:           refund.RefundReason,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to refund payment: %w", err)
+	}
+
+	if resp.StatusCode() != http.StatusOK && resp.StatusCode() != http.StatusNoContent {
+		return fmt.Errorf("unexpected status code for PUT payments-api/refunds: %d", resp.StatusCode())
+	}
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"tickets/entities"
+)
+
+type PaymentsMock struct {
+	mu      sync.Mutex
+	refunds []entities.PaymentRefund
+}
+
+func (p *PaymentsMock) RefundPayment(ctx context.Context, refund entities.PaymentRefund) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.refunds = append(p.refunds, refund)
+
+	return nil
+}
+
+// Refunds returns a copy of the refunds made so far.
+func (p *PaymentsMock) Refunds() []entities.PaymentRefund {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return slices.Clone(p.refunds)
+}
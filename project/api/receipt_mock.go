@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"github.com/google/uuid"
+	"slices"
 	"sync"
 	"tickets/entities"
 	"time"
@@ -10,17 +11,24 @@ import (
 
 type ReceiptsMock struct {
 	mu             sync.Mutex
-	IssuedReceipts []entities.IssueReceiptRequest
+	issuedReceipts []entities.IssueReceiptRequest
 }
 
 func (r *ReceiptsMock) IssueReceipt(ctx context.Context, request entities.IssueReceiptRequest) (entities.IssueReceiptResponse, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	r.IssuedReceipts = append(r.IssuedReceipts, request)
+	r.issuedReceipts = append(r.issuedReceipts, request)
 
 	return entities.IssueReceiptResponse{
 		ReceiptNumber: uuid.NewString(),
 		IssuedAt:      time.Now(),
 	}, nil
 }
+
+func (r *ReceiptsMock) IssuedReceipts() []entities.IssueReceiptRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.issuedReceipts)
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"tickets/config"
+	"tickets/entities"
+	"time"
+)
+
+type SMTPNotificationsClient struct {
+	addr    string
+	host    string
+	from    string
+	auth    smtp.Auth
+	timeout time.Duration
+}
+
+func NewSMTPNotificationsClient(cfg config.SMTP) *SMTPNotificationsClient {
+	if cfg.Addr == "" || cfg.From == "" {
+		panic("NewSMTPNotificationsClient: addr and from are required")
+	}
+	if cfg.Timeout <= 0 {
+		panic("NewSMTPNotificationsClient: timeout must be positive")
+	}
+
+	host, _, err := net.SplitHostPort(cfg.Addr)
+	if err != nil {
+		panic(fmt.Errorf("NewSMTPNotificationsClient: invalid addr %s: %w", cfg.Addr, err))
+	}
+
+	client := &SMTPNotificationsClient{addr: cfg.Addr, host: host, from: cfg.From, timeout: cfg.Timeout}
+
+	if cfg.Username != "" {
+		client.auth = smtp.PlainAuth("", cfg.Username, cfg.Password, host)
+	}
+
+	return client
+}
+
+func (c SMTPNotificationsClient) SendEmail(ctx context.Context, email entities.Email) error {
+	if strings.ContainsAny(email.To, "\r\n") {
+		return fmt.Errorf("invalid recipient address %q", email.To)
+	}
+
+	var msg strings.Builder
+	msg.WriteString("From: " + c.from + "\r\n")
+	msg.WriteString("To: " + email.To + "\r\n")
+	// subjects of other locales may not be ASCII
+	msg.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", email.Subject) + "\r\n")
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(email.Body, "\n", "\r\n"))
+
+	err := c.send(ctx, email.To, []byte(msg.String()))
+	if err != nil {
+		return fmt.Errorf("failed to send email to %s: %w", email.To, err)
+	}
+
+	return nil
+}
+
+// send does what smtp.SendMail does, but gives up when ctx is done or the timeout passes.
+func (c SMTPNotificationsClient) send(ctx context.Context, to string, msg []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return fmt.Errorf("failed to set deadline: %w", err)
+	}
+	// unblocks reads and writes in progress when ctx is canceled before the deadline
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	client, err := smtp.NewClient(conn, c.host)
+	if err != nil {
+		return fmt.Errorf("failed to start session: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: c.host}); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+	if c.auth != nil {
+		if err := client.Auth(c.auth); err != nil {
+			return fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+	if err := client.Mail(c.from); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
@@ -2,23 +2,32 @@ package api
 
 import (
 	"context"
+	"slices"
 	"sync"
 )
 
 type SpreadsheetsMock struct {
 	mu   sync.Mutex
-	Rows map[string][][]string
+	rows map[string][][]string
 }
 
 func (s *SpreadsheetsMock) AppendRow(ctx context.Context, spreadsheetName string, row []string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if s.Rows == nil {
-		s.Rows = make(map[string][][]string)
+	if s.rows == nil {
+		s.rows = make(map[string][][]string)
 	}
 
-	s.Rows[spreadsheetName] = append(s.Rows[spreadsheetName], row)
+	s.rows[spreadsheetName] = append(s.rows[spreadsheetName], row)
 
 	return nil
 }
+
+// Rows returns a copy of the rows appended to the sheet.
+func (s *SpreadsheetsMock) Rows(spreadsheetName string) [][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.rows[spreadsheetName])
+}
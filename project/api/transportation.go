@@ -0,0 +1,113 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"github.com/ThreeDotsLabs/go-event-driven/common/clients"
+	"github.com/ThreeDotsLabs/go-event-driven/common/clients/transportation"
+	"github.com/google/uuid"
+	"net/http"
+	"tickets/entities"
+)
+
+type TransportationClient struct {
+	clients *clients.Clients
+}
+
+func NewTransportationClient(clients *clients.Clients) *TransportationClient {
+	if clients == nil {
+		panic("NewTransportationClient: clients is nil")
+	}
+
+	return &TransportationClient{clients: clients}
+}
+
+func (c TransportationClient) BookTaxi(ctx context.Context, request entities.TaxiBookingRequest) (string, error) {
+	resp, err := c.clients.Transportation.PutTaxiBookingWithResponse(ctx, transportation.TaxiBookingRequest{
+		CustomerEmail:      request.CustomerEmail,
+		IdempotencyKey:     request.IdempotencyKey,
+		NumberOfPassengers: request.NumberOfPassengers,
+		PassengerName:      request.PassengerName,
+		ReferenceId:        request.ReferenceID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to book taxi: %w", err)
+	}
+
+	switch resp.StatusCode() {
+	case http.StatusCreated:
+		return resp.JSON201.BookingId.String(), nil
+	case http.StatusBadRequest:
+		return "", fmt.Errorf("%w: %s", entities.ErrTaxiBookingRejected, resp.Body)
+	default:
+		return "", fmt.Errorf("unexpected status code for PUT transportation-api/taxi-booking: %d", resp.StatusCode())
+	}
+}
+
+func (c TransportationClient) CancelTaxiBooking(ctx context.Context, taxiBookingID string) error {
+	id, err := uuid.Parse(taxiBookingID)
+	if err != nil {
+		return fmt.Errorf("invalid taxi booking id %s: %w", taxiBookingID, err)
+	}
+
+	resp, err := c.clients.Transportation.DeleteTaxiBookingBookingIdWithResponse(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to cancel taxi booking: %w", err)
+	}
+
+	if resp.StatusCode() != http.StatusOK && resp.StatusCode() != http.StatusNoContent {
+		return fmt.Errorf("unexpected status code for DELETE transportation-api/taxi-booking/%s: %d", taxiBookingID, resp.StatusCode())
+	}
+
+	return nil
+}
+
+func (c TransportationClient) BookFlight(ctx context.Context, request entities.FlightBookingRequest) ([]string, error) {
+	flightID, err := uuid.Parse(request.FlightID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid flight id %s: %w", request.FlightID, err)
+	}
+
+	resp, err := c.clients.Transportation.PutFlightTicketsWithResponse(ctx, transportation.BookFlightTicketRequest{
+		CustomerEmail:  request.CustomerEmail,
+		FlightId:       flightID,
+		IdempotencyKey: request.IdempotencyKey,
+		PassengerNames: request.PassengerNames,
+		ReferenceId:    request.ReferenceID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to book flight: %w", err)
+	}
+
+	switch resp.StatusCode() {
+	case http.StatusCreated:
+		ticketIDs := make([]string, 0, len(resp.JSON201.TicketIds))
+		for _, ticketID := range resp.JSON201.TicketIds {
+			ticketIDs = append(ticketIDs, ticketID.String())
+		}
+
+		return ticketIDs, nil
+	case http.StatusBadRequest:
+		return nil, fmt.Errorf("%w: %s", entities.ErrFlightBookingRejected, resp.Body)
+	default:
+		return nil, fmt.Errorf("unexpected status code for PUT transportation-api/flight-tickets: %d", resp.StatusCode())
+	}
+}
+
+func (c TransportationClient) CancelFlightTicket(ctx context.Context, flightTicketID string) error {
+	id, err := uuid.Parse(flightTicketID)
+	if err != nil {
+		return fmt.Errorf("invalid flight ticket id %s: %w", flightTicketID, err)
+	}
+
+	resp, err := c.clients.Transportation.DeleteFlightTicketsTicketIdWithResponse(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to cancel flight ticket: %w", err)
+	}
+
+	if resp.StatusCode() != http.StatusOK && resp.StatusCode() != http.StatusNoContent {
+		return fmt.Errorf("unexpected status code for DELETE transportation-api/flight-tickets/%s: %d", flightTicketID, resp.StatusCode())
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"tickets/entities"
+
+	"github.com/google/uuid"
+)
+
+type TransportationMock struct {
+	mu sync.Mutex
+	// TaxiBookings maps taxi booking IDs to requests.
+	TaxiBookings          map[string]entities.TaxiBookingRequest
+	CancelledTaxiBookings []string
+	// FlightBookings maps flight ticket IDs to requests.
+	FlightBookings         map[string]entities.FlightBookingRequest
+	CancelledFlightTickets []string
+
+	rejectedFlightIDs []string
+}
+
+// RejectFlight makes bookings of the flight fail, as if there were no seats left.
+func (t *TransportationMock) RejectFlight(flightID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.rejectedFlightIDs = append(t.rejectedFlightIDs, flightID)
+}
+
+func (t *TransportationMock) BookTaxi(ctx context.Context, request entities.TaxiBookingRequest) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.TaxiBookings == nil {
+		t.TaxiBookings = make(map[string]entities.TaxiBookingRequest)
+	}
+
+	for id, booking := range t.TaxiBookings {
+		if booking.IdempotencyKey == request.IdempotencyKey {
+			return id, nil
+		}
+	}
+
+	id := uuid.NewString()
+	t.TaxiBookings[id] = request
+
+	return id, nil
+}
+
+func (t *TransportationMock) CancelTaxiBooking(ctx context.Context, taxiBookingID string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.CancelledTaxiBookings = append(t.CancelledTaxiBookings, taxiBookingID)
+
+	return nil
+}
+
+// CancelledTaxiBookingIDs returns the IDs of the taxi bookings cancelled so far.
+func (t *TransportationMock) CancelledTaxiBookingIDs() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return slices.Clone(t.CancelledTaxiBookings)
+}
+
+func (t *TransportationMock) BookFlight(ctx context.Context, request entities.FlightBookingRequest) ([]string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if slices.Contains(t.rejectedFlightIDs, request.FlightID) {
+		return nil, entities.ErrFlightBookingRejected
+	}
+
+	if t.FlightBookings == nil {
+		t.FlightBookings = make(map[string]entities.FlightBookingRequest)
+	}
+
+	var ticketIDs []string
+	for id, booking := range t.FlightBookings {
+		if booking.IdempotencyKey == request.IdempotencyKey {
+			ticketIDs = append(ticketIDs, id)
+		}
+	}
+	if len(ticketIDs) > 0 {
+		return ticketIDs, nil
+	}
+
+	for range request.PassengerNames {
+		id := uuid.NewString()
+		t.FlightBookings[id] = request
+		ticketIDs = append(ticketIDs, id)
+	}
+
+	return ticketIDs, nil
+}
+
+func (t *TransportationMock) CancelFlightTicket(ctx context.Context, flightTicketID string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.CancelledFlightTickets = append(t.CancelledFlightTickets, flightTicketID)
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"tickets/config"
+	"tickets/entities"
+)
+
+type WebhookClient struct {
+	client *http.Client
+}
+
+func NewWebhookClient(cfg config.Webhooks) *WebhookClient {
+	dialer := &net.Dialer{}
+	if !cfg.AllowPrivateAddresses {
+		// checked when connecting, so hosts resolving to private addresses and redirects to them are refused too
+		dialer.Control = func(network string, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			ip := net.ParseIP(host)
+			if ip == nil || !entities.IsPublicWebhookAddress(ip) {
+				return fmt.Errorf("webhook address %s is not public", host)
+			}
+
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &WebhookClient{client: &http.Client{Timeout: cfg.Timeout, Transport: transport}}
+}
+
+// SendWebhook returns the status code of the endpoint's response (0 when there was none)
+// and an error when the endpoint didn't respond with 2xx.
+func (c WebhookClient) SendWebhook(ctx context.Context, request entities.WebhookRequest) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, request.URL, bytes.NewReader(request.Body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+
+	for key, value := range request.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// drained, so the connection can be reused
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status code for POST %s: %d", request.URL, resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
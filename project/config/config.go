@@ -0,0 +1,590 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileEnv is the environment variable pointing to an optional YAML config file.
+const FileEnv = "CONFIG_FILE"
+
+var (
+	// sheetEvents and emailEvents are the events sheets and emails can be configured for.
+	sheetEvents = []string{"TicketBookingConfirmed", "TicketRefundCalculated"}
+	emailEvents = []string{"TicketBookingConfirmed", "TicketRefundCalculated", "ShowReminderDue", "WaitlistOfferMade"}
+
+	// renamedEvents maps event names of config files written for earlier versions to the events replacing them,
+	// their sheets and emails are merged over the defaults of the new names.
+	// Refund rows and emails were configured for TicketBookingCanceled before refunds were calculated.
+	renamedEvents = map[string]string{"TicketBookingCanceled": "TicketRefundCalculated"}
+)
+
+type Config struct {
+	HTTP     HTTP     `yaml:"http"`
+	Postgres Postgres `yaml:"postgres"`
+	Redis    Redis    `yaml:"redis"`
+	Gateway  Gateway  `yaml:"gateway"`
+	SMTP     SMTP     `yaml:"smtp"`
+	Messages Messages `yaml:"messages"`
+	Sheets   Sheets   `yaml:"sheets"`
+	// Notifications are emails sent to customers.
+	Notifications Notifications `yaml:"notifications"`
+	Webhooks      Webhooks      `yaml:"webhooks"`
+	Reservations  Reservations  `yaml:"reservations"`
+	Waitlist      Waitlist      `yaml:"waitlist"`
+	Shutdown      Shutdown      `yaml:"shutdown"`
+}
+
+type HTTP struct {
+	Port int `yaml:"port"`
+}
+
+type Postgres struct {
+	URL string `yaml:"url"`
+}
+
+type Redis struct {
+	Addr string `yaml:"addr"`
+	// PoolSize should be larger than the number of message handlers,
+	// as each of them holds a connection while waiting for messages.
+	PoolSize int `yaml:"pool_size"`
+}
+
+type Gateway struct {
+	Addr string `yaml:"addr"`
+}
+
+// SMTP is optional, customers aren't emailed when Addr is empty.
+type SMTP struct {
+	Addr string `yaml:"addr"`
+	From string `yaml:"from"`
+	// Username and Password are used for PLAIN authentication, which is skipped when Username is empty.
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	// Timeout bounds a whole delivery, from dialing the server to QUIT.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+func (s SMTP) Enabled() bool {
+	return s.Addr != ""
+}
+
+type Messages struct {
+	ConsumerGroupPrefix string  `yaml:"consumer_group_prefix"`
+	Retry               Retry   `yaml:"retry"`
+	Delayed             Delayed `yaml:"delayed"`
+}
+
+// Delayed configures the relay publishing delayed messages when they're due.
+type Delayed struct {
+	// PollInterval is how often due messages are looked up, so it's also how late they may be published.
+	PollInterval time.Duration `yaml:"poll_interval"`
+	BatchSize    int           `yaml:"batch_size"`
+	// ClaimTimeout is how long a relay has to publish the messages it claimed,
+	// after that they're published again by any relay.
+	ClaimTimeout time.Duration `yaml:"claim_timeout"`
+}
+
+type Retry struct {
+	MaxRetries      int           `yaml:"max_retries"`
+	InitialInterval time.Duration `yaml:"initial_interval"`
+	MaxInterval     time.Duration `yaml:"max_interval"`
+	Multiplier      float64       `yaml:"multiplier"`
+}
+
+// Sheets maps event names (for example "TicketBookingConfirmed") to the sheet their rows are appended to.
+type Sheets map[string]Sheet
+
+type Sheet struct {
+	Name string `yaml:"name"`
+	// Columns are text/template templates rendered against the event's JSON fields
+	// (for example "{{.ticket_id}}" or "{{.header.published_at}}") and "{{.correlation_id}}".
+	Columns []string `yaml:"columns"`
+}
+
+// UnmarshalYAML merges each configured sheet over its defaults, so a file can override only its name.
+func (s *Sheets) UnmarshalYAML(value *yaml.Node) error {
+	if *s == nil {
+		*s = Sheets{}
+	}
+
+	return mergeEntries(value, *s, renamedEvents, func(sheet Sheet, node *yaml.Node) (Sheet, error) {
+		err := node.Decode(&sheet)
+		return sheet, err
+	})
+}
+
+type Notifications struct {
+	// DefaultLocale is used for customers without a locale or with a locale that has no template.
+	DefaultLocale string `yaml:"default_locale"`
+	Emails        Emails `yaml:"emails"`
+	// ShowReminderBefore is how long before the show starts customers get a reminder of their ticket.
+	ShowReminderBefore time.Duration `yaml:"show_reminder_before"`
+}
+
+// Emails maps event names (for example "TicketBookingConfirmed") to email templates per locale.
+type Emails map[string]map[string]Email
+
+// UnmarshalYAML merges the configured templates over the defaults, so a file can add a locale to an event
+// or override only the subject of a template.
+func (e *Emails) UnmarshalYAML(value *yaml.Node) error {
+	if *e == nil {
+		*e = Emails{}
+	}
+
+	return mergeEntries(value, *e, renamedEvents, func(locales map[string]Email, node *yaml.Node) (map[string]Email, error) {
+		locales = maps.Clone(locales)
+		if locales == nil {
+			locales = map[string]Email{}
+		}
+
+		err := mergeEntries(node, locales, nil, func(email Email, node *yaml.Node) (Email, error) {
+			err := node.Decode(&email)
+			return email, err
+		})
+		return locales, err
+	})
+}
+
+// mergeEntries decodes the entries of a YAML mapping over the target's values of the same keys,
+// yaml.v3 would replace them whole. Entries of renamed keys are merged into the new keys first,
+// so the entries of the new keys take precedence.
+func mergeEntries[T any](
+	value *yaml.Node,
+	target map[string]T,
+	renamed map[string]string,
+	merge func(current T, node *yaml.Node) (T, error),
+) error {
+	var entries map[string]yaml.Node
+	err := value.Decode(&entries)
+	if err != nil {
+		return err
+	}
+
+	keys := slices.Sorted(maps.Keys(entries))
+	slices.SortStableFunc(keys, func(a, b string) int {
+		_, aRenamed := renamed[a]
+		_, bRenamed := renamed[b]
+		if aRenamed == bRenamed {
+			return 0
+		}
+		if aRenamed {
+			return -1
+		}
+		return 1
+	})
+
+	for _, key := range keys {
+		name := key
+		if newName, ok := renamed[key]; ok {
+			name = newName
+		}
+
+		node := entries[key]
+		merged, err := merge(target[name], &node)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		target[name] = merged
+	}
+
+	return nil
+}
+
+// Email templates are text/template templates rendered against the event's JSON fields, like sheet columns.
+type Email struct {
+	Subject string `yaml:"subject"`
+	Body    string `yaml:"body"`
+}
+
+type Webhooks struct {
+	// MaxAttempts is how many times an event is sent to an endpoint before giving up.
+	MaxAttempts int `yaml:"max_attempts"`
+	// RetryInterval is the time before the second attempt, it doubles with every further attempt.
+	RetryInterval time.Duration `yaml:"retry_interval"`
+	// DisableAfterFailures disables an endpoint after that many events in a row couldn't be delivered to it.
+	DisableAfterFailures int           `yaml:"disable_after_failures"`
+	Timeout              time.Duration `yaml:"timeout"`
+	// AdminToken is the bearer token required by the admin endpoints: webhooks, promo codes and /ops.
+	// They're disabled when it's empty.
+	AdminToken string `yaml:"admin_token"`
+	// AllowPrivateAddresses allows endpoints on loopback and private networks, for local development only.
+	AllowPrivateAddresses bool `yaml:"allow_private_addresses"`
+}
+
+type Reservations struct {
+	// TTL is how long booked tickets are held before they're released, unless one of them is confirmed.
+	TTL time.Duration `yaml:"ttl"`
+	// CheckInterval is how often expired reservations are looked up.
+	CheckInterval time.Duration `yaml:"check_interval"`
+}
+
+type Waitlist struct {
+	// OfferTTL is how long freed tickets are held for a waiting customer before they're offered to the next one.
+	OfferTTL time.Duration `yaml:"offer_ttl"`
+}
+
+type Shutdown struct {
+	// HTTPServerTimeout is how long in-flight HTTP requests have to finish after the server stops accepting new ones.
+	HTTPServerTimeout time.Duration `yaml:"http_server_timeout"`
+	// HandlersDrainTimeout is how long in-flight message handlers have to finish after the router stops consuming.
+	HandlersDrainTimeout time.Duration `yaml:"handlers_drain_timeout"`
+}
+
+func Default() Config {
+	return Config{
+		HTTP: HTTP{
+			Port: 8080,
+		},
+		Redis: Redis{
+			PoolSize: 100,
+		},
+		SMTP: SMTP{
+			Timeout: 10 * time.Second,
+		},
+		Messages: Messages{
+			ConsumerGroupPrefix: "svc.tickets",
+			Retry: Retry{
+				MaxRetries:      10,
+				InitialInterval: time.Millisecond * 100,
+				MaxInterval:     time.Second,
+				Multiplier:      2,
+			},
+			Delayed: Delayed{
+				PollInterval: time.Second,
+				BatchSize:    100,
+				ClaimTimeout: 30 * time.Second,
+			},
+		},
+		Sheets: Sheets{
+			"TicketBookingConfirmed": {
+				Name:    "tickets-to-print",
+				Columns: append(defaultTicketColumns(), "{{with .seat}}{{.section}}/{{.row}}/{{.number}}{{end}}"),
+			},
+			"TicketRefundCalculated": {
+				Name:    "tickets-to-refund",
+				Columns: []string{"{{.ticket_id}}", "{{.customer_email}}", "{{.refund.amount}}", "{{.refund.currency}}"},
+			},
+		},
+		Notifications: Notifications{
+			DefaultLocale: "en",
+			Emails: Emails{
+				"TicketBookingConfirmed": {
+					"en": {
+						Subject: "Your ticket {{.ticket_id}} is confirmed",
+						Body: "Hello,\n\n" +
+							"your ticket {{.ticket_id}} for {{.price.amount}} {{.price.currency}} is confirmed.\n\n" +
+							"See you at the show!\n",
+					},
+				},
+				"TicketRefundCalculated": {
+					"en": {
+						Subject: "Your ticket {{.ticket_id}} was canceled",
+						Body: "Hello,\n\n" +
+							"your ticket {{.ticket_id}} was canceled. " +
+							"We will refund {{.refund.amount}} {{.refund.currency}} to you.\n",
+					},
+				},
+				"WaitlistOfferMade": {
+					"en": {
+						Subject: "Tickets are waiting for you",
+						Body: "Hello,\n\n" +
+							"{{.number_of_tickets}} tickets you were waiting for are booked for you as {{.booking_id}}. " +
+							"Claim them with entry {{.entry_id}} before {{.expires_at}}, " +
+							"or they will be offered to the next customer.\n",
+					},
+				},
+				"ShowReminderDue": {
+					"en": {
+						Subject: "Reminder: {{.title}} is coming up",
+						Body: "Hello,\n\n" +
+							"{{.title}} at {{.venue}} starts at {{.start_time}}.\n" +
+							"Your ticket is {{.ticket_id}}.\n\n" +
+							"See you at the show!\n",
+					},
+				},
+			},
+			ShowReminderBefore: 24 * time.Hour,
+		},
+		Webhooks: Webhooks{
+			MaxAttempts:          5,
+			RetryInterval:        30 * time.Second,
+			DisableAfterFailures: 3,
+			Timeout:              5 * time.Second,
+		},
+		Reservations: Reservations{
+			TTL:           15 * time.Minute,
+			CheckInterval: 10 * time.Second,
+		},
+		Waitlist: Waitlist{
+			OfferTTL: 30 * time.Minute,
+		},
+		Shutdown: Shutdown{
+			HTTPServerTimeout:    10 * time.Second,
+			HandlersDrainTimeout: 30 * time.Second,
+		},
+	}
+}
+
+func defaultTicketColumns() []string {
+	return []string{"{{.ticket_id}}", "{{.customer_email}}", "{{.price.amount}}", "{{.price.currency}}"}
+}
+
+// Load builds the config from defaults, the optional YAML file from CONFIG_FILE and environment variables,
+// in that order of precedence, and validates the result.
+func Load() (Config, error) {
+	cfg := Default()
+
+	if path := os.Getenv(FileEnv); path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return Config{}, err
+		}
+	}
+
+	if err := cfg.loadEnv(); err != nil {
+		return Config{}, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	err = yaml.Unmarshal(content, c)
+	if err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (c *Config) loadEnv() error {
+	var errs []error
+
+	lookupString("POSTGRES_URL", &c.Postgres.URL)
+	lookupString("REDIS_ADDR", &c.Redis.Addr)
+	lookupString("GATEWAY_ADDR", &c.Gateway.Addr)
+	lookupString("SMTP_ADDR", &c.SMTP.Addr)
+	lookupString("SMTP_FROM", &c.SMTP.From)
+	lookupString("SMTP_USERNAME", &c.SMTP.Username)
+	lookupString("SMTP_PASSWORD", &c.SMTP.Password)
+	lookupString("NOTIFICATIONS_DEFAULT_LOCALE", &c.Notifications.DefaultLocale)
+	lookupString("CONSUMER_GROUP_PREFIX", &c.Messages.ConsumerGroupPrefix)
+	lookupString("WEBHOOKS_ADMIN_TOKEN", &c.Webhooks.AdminToken)
+	c.lookupSheetName("SHEET_TICKETS_TO_PRINT", "TicketBookingConfirmed")
+	c.lookupSheetName("SHEET_TICKETS_TO_REFUND", "TicketRefundCalculated")
+
+	errs = append(errs,
+		lookupInt("HTTP_PORT", &c.HTTP.Port),
+		lookupInt("REDIS_POOL_SIZE", &c.Redis.PoolSize),
+		lookupDuration("SMTP_TIMEOUT", &c.SMTP.Timeout),
+		lookupInt("RETRY_MAX_RETRIES", &c.Messages.Retry.MaxRetries),
+		lookupDuration("RETRY_INITIAL_INTERVAL", &c.Messages.Retry.InitialInterval),
+		lookupDuration("RETRY_MAX_INTERVAL", &c.Messages.Retry.MaxInterval),
+		lookupFloat("RETRY_MULTIPLIER", &c.Messages.Retry.Multiplier),
+		lookupDuration("DELAYED_POLL_INTERVAL", &c.Messages.Delayed.PollInterval),
+		lookupInt("DELAYED_BATCH_SIZE", &c.Messages.Delayed.BatchSize),
+		lookupDuration("DELAYED_CLAIM_TIMEOUT", &c.Messages.Delayed.ClaimTimeout),
+		lookupDuration("SHOW_REMINDER_BEFORE", &c.Notifications.ShowReminderBefore),
+		lookupInt("WEBHOOKS_MAX_ATTEMPTS", &c.Webhooks.MaxAttempts),
+		lookupInt("WEBHOOKS_DISABLE_AFTER_FAILURES", &c.Webhooks.DisableAfterFailures),
+		lookupDuration("WEBHOOKS_TIMEOUT", &c.Webhooks.Timeout),
+		lookupDuration("WEBHOOKS_RETRY_INTERVAL", &c.Webhooks.RetryInterval),
+		lookupBool("WEBHOOKS_ALLOW_PRIVATE_ADDRESSES", &c.Webhooks.AllowPrivateAddresses),
+		lookupDuration("RESERVATIONS_TTL", &c.Reservations.TTL),
+		lookupDuration("RESERVATIONS_CHECK_INTERVAL", &c.Reservations.CheckInterval),
+		lookupDuration("WAITLIST_OFFER_TTL", &c.Waitlist.OfferTTL),
+		lookupDuration("SHUTDOWN_HTTP_SERVER_TIMEOUT", &c.Shutdown.HTTPServerTimeout),
+		lookupDuration("SHUTDOWN_HANDLERS_DRAIN_TIMEOUT", &c.Shutdown.HandlersDrainTimeout),
+	)
+
+	return errors.Join(errs...)
+}
+
+func (c Config) Validate() error {
+	var errs []error
+
+	if c.HTTP.Port <= 0 || c.HTTP.Port > 65535 {
+		errs = append(errs, fmt.Errorf("http.port must be between 1 and 65535, got %d", c.HTTP.Port))
+	}
+	if c.Postgres.URL == "" {
+		errs = append(errs, errors.New("postgres.url (POSTGRES_URL) is required"))
+	}
+	if c.Redis.Addr == "" {
+		errs = append(errs, errors.New("redis.addr (REDIS_ADDR) is required"))
+	}
+	if c.Redis.PoolSize < 1 {
+		errs = append(errs, errors.New("redis.pool_size must be at least 1"))
+	}
+	if c.Gateway.Addr == "" {
+		errs = append(errs, errors.New("gateway.addr (GATEWAY_ADDR) is required"))
+	}
+	if (c.SMTP.Addr == "") != (c.SMTP.From == "") {
+		errs = append(errs, errors.New("smtp.addr (SMTP_ADDR) and smtp.from (SMTP_FROM) must be set together"))
+	}
+	if c.SMTP.Timeout <= 0 {
+		errs = append(errs, errors.New("smtp.timeout must be positive"))
+	}
+	if c.Messages.ConsumerGroupPrefix == "" {
+		errs = append(errs, errors.New("messages.consumer_group_prefix is required"))
+	}
+	if c.Messages.Retry.MaxRetries < 0 {
+		errs = append(errs, errors.New("messages.retry.max_retries can't be negative"))
+	}
+	if c.Messages.Retry.InitialInterval <= 0 {
+		errs = append(errs, errors.New("messages.retry.initial_interval must be positive"))
+	}
+	if c.Messages.Retry.MaxInterval < c.Messages.Retry.InitialInterval {
+		errs = append(errs, errors.New("messages.retry.max_interval can't be lower than initial_interval"))
+	}
+	if c.Messages.Retry.Multiplier < 1 {
+		errs = append(errs, errors.New("messages.retry.multiplier must be at least 1"))
+	}
+	if c.Messages.Delayed.PollInterval <= 0 {
+		errs = append(errs, errors.New("messages.delayed.poll_interval must be positive"))
+	}
+	if c.Messages.Delayed.BatchSize < 1 {
+		errs = append(errs, errors.New("messages.delayed.batch_size must be at least 1"))
+	}
+	if c.Messages.Delayed.ClaimTimeout <= 0 {
+		errs = append(errs, errors.New("messages.delayed.claim_timeout must be positive"))
+	}
+	for eventName, sheet := range c.Sheets {
+		if !slices.Contains(sheetEvents, eventName) {
+			errs = append(errs, fmt.Errorf("sheets.%s is not an event with a sheet, known events: %v", eventName, sheetEvents))
+		}
+		if sheet.Name == "" {
+			errs = append(errs, fmt.Errorf("sheets.%s.name is required", eventName))
+		}
+		if len(sheet.Columns) == 0 {
+			errs = append(errs, fmt.Errorf("sheets.%s.columns can't be empty", eventName))
+		}
+	}
+	if c.Notifications.DefaultLocale == "" {
+		errs = append(errs, errors.New("notifications.default_locale is required"))
+	}
+	for eventName, locales := range c.Notifications.Emails {
+		if !slices.Contains(emailEvents, eventName) {
+			errs = append(errs, fmt.Errorf("notifications.emails.%s is not an event with an email, known events: %v", eventName, emailEvents))
+		}
+		if _, ok := locales[c.Notifications.DefaultLocale]; !ok {
+			errs = append(errs, fmt.Errorf("notifications.emails.%s has no template for the default locale %s", eventName, c.Notifications.DefaultLocale))
+		}
+		for locale, email := range locales {
+			if email.Subject == "" || email.Body == "" {
+				errs = append(errs, fmt.Errorf("notifications.emails.%s.%s needs a subject and a body", eventName, locale))
+			}
+		}
+	}
+	if c.Notifications.ShowReminderBefore <= 0 {
+		errs = append(errs, errors.New("notifications.show_reminder_before must be positive"))
+	}
+	if c.Webhooks.MaxAttempts < 1 || c.Webhooks.DisableAfterFailures < 1 {
+		errs = append(errs, errors.New("webhooks.max_attempts and webhooks.disable_after_failures must be at least 1"))
+	}
+	if c.Webhooks.Timeout <= 0 || c.Webhooks.RetryInterval <= 0 {
+		errs = append(errs, errors.New("webhooks.timeout and webhooks.retry_interval must be positive"))
+	}
+	if c.Reservations.TTL <= 0 || c.Reservations.CheckInterval <= 0 {
+		errs = append(errs, errors.New("reservations.ttl and reservations.check_interval must be positive"))
+	}
+	if c.Waitlist.OfferTTL <= 0 {
+		errs = append(errs, errors.New("waitlist.offer_ttl must be positive"))
+	}
+	if c.Shutdown.HTTPServerTimeout <= 0 || c.Shutdown.HandlersDrainTimeout <= 0 {
+		errs = append(errs, errors.New("shutdown timeouts must be positive"))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Config) lookupSheetName(key string, eventName string) {
+	sheet, ok := c.Sheets[eventName]
+	if !ok {
+		return
+	}
+
+	lookupString(key, &sheet.Name)
+	c.Sheets[eventName] = sheet
+}
+
+func lookupString(key string, target *string) {
+	if value, ok := os.LookupEnv(key); ok {
+		*target = value
+	}
+}
+
+func lookupInt(key string, target *int) error {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", key, err)
+	}
+
+	*target = parsed
+	return nil
+}
+
+func lookupFloat(key string, target *float64) error {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return nil
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", key, err)
+	}
+
+	*target = parsed
+	return nil
+}
+
+func lookupBool(key string, target *bool) error {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return nil
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", key, err)
+	}
+
+	*target = parsed
+	return nil
+}
+
+func lookupDuration(key string, target *time.Duration) error {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return nil
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", key, err)
+	}
+
+	*target = parsed
+	return nil
+}
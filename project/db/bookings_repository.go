@@ -0,0 +1,564 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"tickets/entities"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const uniqueViolation = "23505"
+
+type BookingsRepository struct {
+	db *sqlx.DB
+}
+
+type bookingRow struct {
+	entities.Booking
+	OriginalPrice []byte `db:"original_price"`
+	Price         []byte `db:"price"`
+}
+
+func (r bookingRow) booking() (entities.Booking, error) {
+	booking := r.Booking
+
+	if len(r.OriginalPrice) > 0 {
+		err := json.Unmarshal(r.OriginalPrice, &booking.OriginalPrice)
+		if err != nil {
+			return entities.Booking{}, fmt.Errorf("could not unmarshal original price of booking %s: %w", booking.BookingID, err)
+		}
+	}
+
+	if len(r.Price) > 0 {
+		err := json.Unmarshal(r.Price, &booking.Price)
+		if err != nil {
+			return entities.Booking{}, fmt.Errorf("could not unmarshal price of booking %s: %w", booking.BookingID, err)
+		}
+	}
+
+	return booking, nil
+}
+
+func NewBookingsRepository(db *sqlx.DB) BookingsRepository {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return BookingsRepository{db: db}
+}
+
+// AddBooking stores the booking if the show has enough tickets left, and its seats if they are free.
+// The show's row is locked until the transaction ends, so concurrent bookings of the same show can't oversell it.
+// Adding a booking that already exists is a no-op, so redelivered commands don't fail.
+// The outbox messages are stored with the booking, so they're published once it's committed.
+func (r BookingsRepository) AddBooking(ctx context.Context, booking entities.Booking, outbox ...entities.DelayedMessage) error {
+	return updateInTx(ctx, r.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		show, err := lockShow(ctx, tx, booking.ShowID)
+		if err != nil {
+			return err
+		}
+
+		added, err := addBooking(ctx, tx, show, booking)
+		if err != nil || !added {
+			return err
+		}
+
+		return addDelayedMessages(ctx, tx, outbox)
+	})
+}
+
+// lockShow locks the show's row until the transaction ends, so its tickets can't be booked concurrently.
+func lockShow(ctx context.Context, tx *sqlx.Tx, showID string) (entities.Show, error) {
+	var row showRow
+	err := tx.GetContext(ctx, &row, `
+		SELECT * FROM shows WHERE show_id = $1 FOR UPDATE
+	`, showID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.Show{}, entities.ErrShowNotFound
+	}
+	if err != nil {
+		return entities.Show{}, fmt.Errorf("could not get show %s: %w", showID, err)
+	}
+
+	return row.show()
+}
+
+// addBooking adds the booking of the show locked by lockShow, it returns false if the booking exists already.
+func addBooking(ctx context.Context, tx *sqlx.Tx, show entities.Show, booking entities.Booking) (bool, error) {
+	var existingBookings int
+	err := tx.GetContext(ctx, &existingBookings, `
+		SELECT COUNT(*) FROM bookings WHERE booking_id = $1
+	`, booking.BookingID)
+	if err != nil {
+		return false, fmt.Errorf("could not check booking %s: %w", booking.BookingID, err)
+	}
+	if existingBookings > 0 {
+		return false, nil
+	}
+
+	booked, err := bookedTickets(ctx, tx, booking.ShowID)
+	if err != nil {
+		return false, err
+	}
+
+	if booked+booking.NumberOfTickets > show.NumberOfTickets {
+		return false, entities.ErrNotEnoughTickets
+	}
+
+	if booking.PromoCode != nil {
+		err = checkPromoCodeUses(ctx, tx, *booking.PromoCode, booking.CustomerEmail)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	// bookings without a promo code store JSON null
+	originalPrice, err := json.Marshal(booking.OriginalPrice)
+	if err != nil {
+		return false, fmt.Errorf("could not marshal original price: %w", err)
+	}
+
+	price, err := json.Marshal(booking.Price)
+	if err != nil {
+		return false, fmt.Errorf("could not marshal price: %w", err)
+	}
+
+	_, err = tx.NamedExecContext(ctx, `
+		INSERT INTO bookings (
+			booking_id, show_id, number_of_tickets, customer_email, vip, expires_at, promo_code, original_price, price
+		)
+		VALUES (
+			:booking_id, :show_id, :number_of_tickets, :customer_email, :vip, :expires_at, :promo_code, :original_price, :price
+		)
+	`, bookingRow{Booking: booking, OriginalPrice: originalPrice, Price: price})
+	if err != nil {
+		return false, fmt.Errorf("could not add booking %s: %w", booking.BookingID, err)
+	}
+
+	err = addBookedSeats(ctx, tx, show, booking)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func bookedTickets(ctx context.Context, tx *sqlx.Tx, showID string) (int, error) {
+	var booked int
+	err := tx.GetContext(ctx, &booked, `
+		SELECT COALESCE(SUM(number_of_tickets), 0) FROM bookings WHERE show_id = $1
+	`, showID)
+	if err != nil {
+		return 0, fmt.Errorf("could not get booked tickets for show %s: %w", showID, err)
+	}
+
+	return booked, nil
+}
+
+// checkPromoCodeUses checks the usage limits of the promo code, counting the bookings made with it.
+// The code's row is locked until the transaction ends, so concurrent bookings can't exceed the limits.
+func checkPromoCodeUses(ctx context.Context, tx *sqlx.Tx, code string, customerEmail string) error {
+	var promoCode entities.PromoCode
+	err := tx.GetContext(ctx, &promoCode, `
+		SELECT max_uses, max_uses_per_customer FROM promo_codes WHERE code = $1 FOR UPDATE
+	`, code)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.ErrPromoCodeNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("could not get promo code %s: %w", code, err)
+	}
+
+	var uses struct {
+		Total      int `db:"total"`
+		ByCustomer int `db:"by_customer"`
+	}
+	err = tx.GetContext(ctx, &uses, `
+		SELECT COUNT(*) AS total, COUNT(CASE WHEN customer_email = $2 THEN 1 END) AS by_customer
+		FROM bookings WHERE promo_code = $1
+	`, code, customerEmail)
+	if err != nil {
+		return fmt.Errorf("could not count uses of promo code %s: %w", code, err)
+	}
+
+	if promoCode.MaxUses != nil && uses.Total >= *promoCode.MaxUses {
+		return entities.ErrPromoCodeUsedUp
+	}
+	if promoCode.MaxUsesPerCustomer != nil && uses.ByCustomer >= *promoCode.MaxUsesPerCustomer {
+		return entities.ErrPromoCodeCustomerLimitReached
+	}
+
+	return nil
+}
+
+// addBookedSeats locks the booking's seats. The primary key of booked_seats guarantees a seat can't be booked twice,
+// even if a booking passed the check of free seats concurrently.
+func addBookedSeats(ctx context.Context, tx *sqlx.Tx, show entities.Show, booking entities.Booking) error {
+	if show.SeatMap == nil {
+		if len(booking.Seats) > 0 {
+			return entities.ErrSeatsNotAllowed
+		}
+		return nil
+	}
+
+	if len(booking.Seats) != booking.NumberOfTickets {
+		return entities.ErrSeatsRequired
+	}
+
+	chosen := make(map[entities.Seat]bool, len(booking.Seats))
+	for _, seat := range booking.Seats {
+		if !show.SeatMap.Contains(seat) {
+			return fmt.Errorf("%w: %s", entities.ErrSeatNotFound, seat)
+		}
+		if chosen[seat] {
+			return fmt.Errorf("%w: %s", entities.ErrSeatTaken, seat)
+		}
+		chosen[seat] = true
+
+		var taken int
+		err := tx.GetContext(ctx, &taken, `
+			SELECT COUNT(*) FROM booked_seats
+			WHERE show_id = $1 AND section = $2 AND seat_row = $3 AND seat_number = $4
+		`, show.ShowID, seat.Section, seat.Row, seat.Number)
+		if err != nil {
+			return fmt.Errorf("could not check seat %s of show %s: %w", seat, show.ShowID, err)
+		}
+		if taken > 0 {
+			return fmt.Errorf("%w: %s", entities.ErrSeatTaken, seat)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO booked_seats (show_id, section, seat_row, seat_number, booking_id)
+			VALUES ($1, $2, $3, $4, $5)
+		`, show.ShowID, seat.Section, seat.Row, seat.Number, booking.BookingID)
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return fmt.Errorf("%w: %s", entities.ErrSeatTaken, seat)
+		}
+		if err != nil {
+			return fmt.Errorf("could not book seat %s of show %s: %w", seat, show.ShowID, err)
+		}
+	}
+
+	return nil
+}
+
+func (r BookingsRepository) BookingByID(ctx context.Context, bookingID string) (entities.Booking, error) {
+	var row bookingRow
+	err := r.db.GetContext(ctx, &row, `SELECT * FROM bookings WHERE booking_id = $1`, bookingID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.Booking{}, entities.ErrBookingNotFound
+	}
+	if err != nil {
+		return entities.Booking{}, fmt.Errorf("could not get booking %s: %w", bookingID, err)
+	}
+
+	return row.booking()
+}
+
+// SetTaxiBookingID returns entities.ErrTaxiCancelled when the taxi was cancelled before it was booked,
+// the caller is then responsible for cancelling taxiBookingID.
+func (r BookingsRepository) SetTaxiBookingID(ctx context.Context, bookingID string, taxiBookingID string) error {
+	return updateInTx(ctx, r.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		var taxiCancelled bool
+		err := tx.GetContext(ctx, &taxiCancelled, `
+			SELECT taxi_cancelled FROM bookings WHERE booking_id = $1 FOR UPDATE
+		`, bookingID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.ErrBookingNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("could not get booking %s: %w", bookingID, err)
+		}
+
+		if taxiCancelled {
+			return entities.ErrTaxiCancelled
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE bookings SET taxi_booking_id = $1 WHERE booking_id = $2
+		`, taxiBookingID, bookingID)
+		if err != nil {
+			return fmt.Errorf("could not set taxi booking of booking %s: %w", bookingID, err)
+		}
+
+		return nil
+	})
+}
+
+func (r BookingsRepository) MarkTaxiCancelled(ctx context.Context, bookingID string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE bookings SET taxi_cancelled = TRUE WHERE booking_id = $1
+	`, bookingID)
+	if err != nil {
+		return fmt.Errorf("could not mark taxi of booking %s as cancelled: %w", bookingID, err)
+	}
+
+	return nil
+}
+
+// RemoveBooking releases the booking's tickets and seats, removing a booking that doesn't exist is a no-op.
+func (r BookingsRepository) RemoveBooking(ctx context.Context, bookingID string) error {
+	return updateInTx(ctx, r.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		return removeBooking(ctx, tx, bookingID)
+	})
+}
+
+func removeBooking(ctx context.Context, tx *sqlx.Tx, bookingID string) error {
+	_, err := tx.ExecContext(ctx, `DELETE FROM booked_seats WHERE booking_id = $1`, bookingID)
+	if err != nil {
+		return fmt.Errorf("could not release seats of booking %s: %w", bookingID, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM bookings WHERE booking_id = $1`, bookingID)
+	if err != nil {
+		return fmt.Errorf("could not remove booking %s: %w", bookingID, err)
+	}
+
+	return nil
+}
+
+// ReleaseTicket releases the place and the seat of the booking's canceled ticket, so they can be booked again,
+// and returns the booking's show. Releasing a ticket again is a no-op, so redelivered events release it once.
+// The booking is kept with its remaining tickets, as it's still looked up by the events of the canceled ticket.
+func (r BookingsRepository) ReleaseTicket(ctx context.Context, bookingID string, ticketID string) (string, error) {
+	var showID string
+
+	err := updateInTx(ctx, r.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		err := tx.GetContext(ctx, &showID, `
+			SELECT show_id FROM bookings WHERE booking_id = $1 FOR UPDATE
+		`, bookingID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.ErrBookingNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("could not get booking %s: %w", bookingID, err)
+		}
+
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO released_tickets (ticket_id, booking_id) VALUES ($1, $2)
+			ON CONFLICT (ticket_id) DO NOTHING
+		`, ticketID, bookingID)
+		if err != nil {
+			return fmt.Errorf("could not release ticket %s: %w", ticketID, err)
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("could not release ticket %s: %w", ticketID, err)
+		}
+		if affected == 0 {
+			return nil
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE bookings SET number_of_tickets = number_of_tickets - 1
+			WHERE booking_id = $1 AND number_of_tickets > 0
+		`, bookingID)
+		if err != nil {
+			return fmt.Errorf("could not release ticket %s of booking %s: %w", ticketID, bookingID, err)
+		}
+
+		return releaseSeat(ctx, tx, showID, bookingID, ticketID)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return showID, nil
+}
+
+// releaseSeat releases the seat assigned to the ticket, or one of the booking's unassigned seats
+// if the ticket was canceled before it got one.
+func releaseSeat(ctx context.Context, tx *sqlx.Tx, showID string, bookingID string, ticketID string) error {
+	var seats []entities.Seat
+	err := tx.SelectContext(ctx, &seats, `
+		SELECT section, seat_row, seat_number FROM booked_seats WHERE ticket_id = $1
+	`, ticketID)
+	if err != nil {
+		return fmt.Errorf("could not get seat of ticket %s: %w", ticketID, err)
+	}
+
+	if len(seats) == 0 {
+		err = tx.SelectContext(ctx, &seats, `
+			SELECT section, seat_row, seat_number FROM booked_seats
+			WHERE booking_id = $1 AND ticket_id IS NULL
+			ORDER BY section, seat_row, seat_number
+			LIMIT 1
+		`, bookingID)
+		if err != nil {
+			return fmt.Errorf("could not get free seats of booking %s: %w", bookingID, err)
+		}
+	}
+	if len(seats) == 0 {
+		return nil
+	}
+
+	seat := seats[0]
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM booked_seats WHERE show_id = $1 AND section = $2 AND seat_row = $3 AND seat_number = $4
+	`, showID, seat.Section, seat.Row, seat.Number)
+	if err != nil {
+		return fmt.Errorf("could not release seat %s of show %s: %w", seat, showID, err)
+	}
+
+	return nil
+}
+
+// AssignSeat assigns one of the booking's seats to the ticket, or returns the seat assigned to it before.
+// The seat is nil for bookings without seats.
+func (r BookingsRepository) AssignSeat(ctx context.Context, bookingID string, ticketID string) (*entities.Seat, error) {
+	var assigned *entities.Seat
+
+	err := updateInTx(ctx, r.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		var seats []entities.Seat
+		err := tx.SelectContext(ctx, &seats, `
+			SELECT section, seat_row, seat_number FROM booked_seats WHERE ticket_id = $1
+		`, ticketID)
+		if err != nil {
+			return fmt.Errorf("could not get seat of ticket %s: %w", ticketID, err)
+		}
+		if len(seats) > 0 {
+			assigned = &seats[0]
+			return nil
+		}
+
+		// seats locked by concurrently confirmed tickets of the booking are skipped, so each ticket gets another one
+		err = tx.SelectContext(ctx, &seats, `
+			SELECT section, seat_row, seat_number FROM booked_seats
+			WHERE booking_id = $1 AND ticket_id IS NULL
+			ORDER BY section, seat_row, seat_number
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		`, bookingID)
+		if err != nil {
+			return fmt.Errorf("could not get free seats of booking %s: %w", bookingID, err)
+		}
+		if len(seats) == 0 {
+			return nil
+		}
+
+		seat := seats[0]
+		_, err = tx.ExecContext(ctx, `
+			UPDATE booked_seats SET ticket_id = $1
+			WHERE booking_id = $2 AND section = $3 AND seat_row = $4 AND seat_number = $5
+		`, ticketID, bookingID, seat.Section, seat.Row, seat.Number)
+		if err != nil {
+			return fmt.Errorf("could not assign seat %s to ticket %s: %w", seat, ticketID, err)
+		}
+
+		assigned = &seat
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return assigned, nil
+}
+
+// ConfirmBooking keeps the booking from expiring. Bookings which expired at now are not confirmed,
+// as their tickets may be released already; expired bookings are removed, so missing ones are expired too.
+func (r BookingsRepository) ConfirmBooking(ctx context.Context, bookingID string, now time.Time) error {
+	return updateInTx(ctx, r.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		var expiresAt *time.Time
+		err := tx.GetContext(ctx, &expiresAt, `
+			SELECT expires_at FROM bookings WHERE booking_id = $1 FOR UPDATE
+		`, bookingID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.ErrBookingExpired
+		}
+		if err != nil {
+			return fmt.Errorf("could not get booking %s: %w", bookingID, err)
+		}
+
+		if expiresAt == nil {
+			// confirmed before
+			return nil
+		}
+		if !expiresAt.After(now) {
+			return entities.ErrBookingExpired
+		}
+
+		res, err := tx.ExecContext(ctx, `
+			UPDATE bookings SET expires_at = NULL WHERE booking_id = $1 AND expires_at > $2
+		`, bookingID, now)
+		if err != nil {
+			return fmt.Errorf("could not confirm booking %s: %w", bookingID, err)
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("could not confirm booking %s: %w", bookingID, err)
+		}
+		if affected == 0 {
+			return entities.ErrBookingExpired
+		}
+
+		return nil
+	})
+}
+
+// RemoveExpiredBookings removes up to limit bookings expired at now, releasing their tickets.
+// The outbox message returned by outboxFn for each booking is stored with its removal,
+// so it's published once the booking is removed. The bookings are locked until then, so other instances skip them.
+func (r BookingsRepository) RemoveExpiredBookings(
+	ctx context.Context,
+	now time.Time,
+	limit int,
+	outboxFn func(ctx context.Context, booking entities.Booking) (entities.DelayedMessage, error),
+) (int, error) {
+	removed := 0
+
+	err := updateInTx(ctx, r.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		var rows []bookingRow
+		err := tx.SelectContext(ctx, &rows, `
+			SELECT * FROM bookings
+			WHERE expires_at <= $1
+			ORDER BY expires_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		`, now, limit)
+		if err != nil {
+			return fmt.Errorf("could not get expired bookings: %w", err)
+		}
+
+		for _, row := range rows {
+			booking, err := row.booking()
+			if err != nil {
+				return err
+			}
+
+			outboxMessage, err := outboxFn(ctx, booking)
+			if err != nil {
+				return err
+			}
+
+			err = removeBooking(ctx, tx, booking.BookingID)
+			if err != nil {
+				return err
+			}
+
+			err = addDelayedMessages(ctx, tx, []entities.DelayedMessage{outboxMessage})
+			if err != nil {
+				return err
+			}
+
+			removed++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,115 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"tickets/entities"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type DelayedMessagesRepository struct {
+	db *sqlx.DB
+}
+
+func NewDelayedMessagesRepository(db *sqlx.DB) DelayedMessagesRepository {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return DelayedMessagesRepository{db: db}
+}
+
+type delayedMessageRow struct {
+	entities.DelayedMessage
+	Metadata []byte `db:"metadata"`
+}
+
+func (r DelayedMessagesRepository) Add(ctx context.Context, msg entities.DelayedMessage) error {
+	return updateInTx(ctx, r.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		return addDelayedMessages(ctx, tx, []entities.DelayedMessage{msg})
+	})
+}
+
+// addDelayedMessages stores messages in the transaction of another change,
+// so they're published if and only if the change is committed.
+func addDelayedMessages(ctx context.Context, tx *sqlx.Tx, msgs []entities.DelayedMessage) error {
+	for _, msg := range msgs {
+		metadata, err := json.Marshal(msg.Metadata)
+		if err != nil {
+			return fmt.Errorf("could not marshal metadata: %w", err)
+		}
+
+		_, err = tx.NamedExecContext(ctx, `
+			INSERT INTO delayed_messages (message_id, topic, payload, metadata, publish_at)
+			VALUES (:message_id, :topic, :payload, :metadata, :publish_at)
+		`, delayedMessageRow{DelayedMessage: msg, Metadata: metadata})
+		if err != nil {
+			return fmt.Errorf("could not add delayed message %s: %w", msg.MessageID, err)
+		}
+	}
+
+	return nil
+}
+
+// ClaimDue returns up to limit messages due at now, oldest first, claimed until claimedUntil.
+// Claimed messages are skipped by other relays until the claim runs out, so messages that weren't removed
+// by the relay that claimed them are published again.
+func (r DelayedMessagesRepository) ClaimDue(
+	ctx context.Context,
+	now time.Time,
+	claimedUntil time.Time,
+	limit int,
+) ([]entities.DelayedMessage, error) {
+	var msgs []entities.DelayedMessage
+
+	err := updateInTx(ctx, r.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		var rows []delayedMessageRow
+		err := tx.SelectContext(ctx, &rows, `
+			SELECT message_id, topic, payload, metadata, publish_at
+			FROM delayed_messages
+			WHERE publish_at <= $1 AND (claimed_until IS NULL OR claimed_until <= $2)
+			ORDER BY publish_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		`, now, now, limit)
+		if err != nil {
+			return fmt.Errorf("could not get due delayed messages: %w", err)
+		}
+
+		for _, row := range rows {
+			msg := row.DelayedMessage
+			err = json.Unmarshal(row.Metadata, &msg.Metadata)
+			if err != nil {
+				return fmt.Errorf("could not unmarshal metadata of delayed message %s: %w", msg.MessageID, err)
+			}
+
+			_, err = tx.ExecContext(ctx, `
+				UPDATE delayed_messages SET claimed_until = $1 WHERE message_id = $2
+			`, claimedUntil, msg.MessageID)
+			if err != nil {
+				return fmt.Errorf("could not claim delayed message %s: %w", msg.MessageID, err)
+			}
+
+			msgs = append(msgs, msg)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return msgs, nil
+}
+
+func (r DelayedMessagesRepository) Remove(ctx context.Context, messageID string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM delayed_messages WHERE message_id = $1`, messageID)
+	if err != nil {
+		return fmt.Errorf("could not remove delayed message %s: %w", messageID, err)
+	}
+
+	return nil
+}
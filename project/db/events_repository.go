@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"tickets/entities"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type EventsRepository struct {
+	db *sqlx.DB
+}
+
+func NewEventsRepository(db *sqlx.DB) EventsRepository {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return EventsRepository{db: db}
+}
+
+// AddEvent appends the event to the store. A redelivered event is stored only once.
+func (r EventsRepository) AddEvent(ctx context.Context, event entities.StoredEvent) error {
+	_, err := r.db.NamedExecContext(ctx, `
+		INSERT INTO events (event_id, event_name, published_at, correlation_id, payload)
+		VALUES (:event_id, :event_name, :published_at, :correlation_id, :payload)
+		ON CONFLICT (event_id) DO NOTHING
+	`, event)
+	if err != nil {
+		return fmt.Errorf("could not add event %s: %w", event.EventID, err)
+	}
+
+	return nil
+}
+
+func (r EventsRepository) EventsByTicketID(ctx context.Context, ticketID string) ([]entities.StoredEvent, error) {
+	var events []entities.StoredEvent
+	err := r.db.SelectContext(ctx, &events, `
+		SELECT event_id, event_name, published_at, correlation_id, payload
+		FROM events
+		WHERE payload->>'ticket_id' = $1::text
+		ORDER BY published_at, event_id
+	`, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get events of ticket %s: %w", ticketID, err)
+	}
+
+	return events, nil
+}
+
+// ForEachEvent calls fn with every stored event in the order they were published, without loading them all at once.
+func (r EventsRepository) ForEachEvent(ctx context.Context, fn func(ctx context.Context, event entities.StoredEvent) error) error {
+	rows, err := r.db.QueryxContext(ctx, `
+		SELECT event_id, event_name, published_at, correlation_id, payload
+		FROM events
+		ORDER BY published_at, event_id
+	`)
+	if err != nil {
+		return fmt.Errorf("could not get events: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var event entities.StoredEvent
+		err = rows.StructScan(&event)
+		if err != nil {
+			return fmt.Errorf("could not scan event: %w", err)
+		}
+
+		err = fn(ctx, event)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("could not iterate events: %w", err)
+	}
+
+	return nil
+}
+
+func (r EventsRepository) EventExists(ctx context.Context, eventID string) (bool, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM events WHERE event_id = $1`, eventID)
+	if err != nil {
+		return false, fmt.Errorf("could not check event %s: %w", eventID, err)
+	}
+
+	return count > 0, nil
+}
+
+// EventsAfter returns the events published after the event with eventID, in order.
+// No events are returned when eventID is not stored.
+func (r EventsRepository) EventsAfter(ctx context.Context, eventID string) ([]entities.StoredEvent, error) {
+	var last entities.StoredEvent
+	err := r.db.GetContext(ctx, &last, `SELECT event_id, published_at FROM events WHERE event_id = $1`, eventID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get event %s: %w", eventID, err)
+	}
+
+	var events []entities.StoredEvent
+	err = r.db.SelectContext(ctx, &events, `
+		SELECT event_id, event_name, published_at, correlation_id, payload
+		FROM events
+		WHERE published_at >= $1
+		ORDER BY published_at, event_id
+	`, last.PublishedAt)
+	if err != nil {
+		return nil, fmt.Errorf("could not get events after %s: %w", eventID, err)
+	}
+
+	after := make([]entities.StoredEvent, 0, len(events))
+	for _, event := range events {
+		// events published at the same time are ordered by ID
+		if event.PublishedAt.Equal(last.PublishedAt) && event.EventID <= last.EventID {
+			continue
+		}
+
+		after = append(after, event)
+	}
+
+	return after, nil
+}
@@ -0,0 +1,175 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"tickets/entities"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type OpsBookingsRepository struct {
+	db *sqlx.DB
+}
+
+func NewOpsBookingsRepository(db *sqlx.DB) OpsBookingsRepository {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return OpsBookingsRepository{db: db}
+}
+
+// Update locks the read model of the booking until updateFn returns and stores its changes.
+// The read model is created when missing, as events of a booking may be handled in any order.
+func (r OpsBookingsRepository) Update(
+	ctx context.Context,
+	bookingID string,
+	updateFn func(ctx context.Context, opsBooking *entities.OpsBooking) error,
+) error {
+	return updateInTx(ctx, r.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		empty, err := json.Marshal(entities.OpsBooking{
+			BookingID: bookingID,
+			Tickets:   map[string]entities.OpsTicket{},
+		})
+		if err != nil {
+			return fmt.Errorf("could not marshal ops booking: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO ops_bookings (booking_id, payload)
+			VALUES ($1, $2)
+			ON CONFLICT (booking_id) DO NOTHING
+		`, bookingID, empty)
+		if err != nil {
+			return fmt.Errorf("could not add ops booking %s: %w", bookingID, err)
+		}
+
+		var payload []byte
+		err = tx.GetContext(ctx, &payload, `
+			SELECT payload FROM ops_bookings WHERE booking_id = $1 FOR UPDATE
+		`, bookingID)
+		if err != nil {
+			return fmt.Errorf("could not get ops booking %s: %w", bookingID, err)
+		}
+
+		opsBooking, err := unmarshalOpsBooking(payload)
+		if err != nil {
+			return err
+		}
+
+		err = updateFn(ctx, &opsBooking)
+		if err != nil {
+			return err
+		}
+
+		payload, err = json.Marshal(opsBooking)
+		if err != nil {
+			return fmt.Errorf("could not marshal ops booking: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE ops_bookings SET payload = $1 WHERE booking_id = $2
+		`, payload, bookingID)
+		if err != nil {
+			return fmt.Errorf("could not update ops booking %s: %w", bookingID, err)
+		}
+
+		return updateReceiptIssueDates(ctx, tx, opsBooking)
+	})
+}
+
+func updateReceiptIssueDates(ctx context.Context, tx *sqlx.Tx, opsBooking entities.OpsBooking) error {
+	_, err := tx.ExecContext(ctx, `
+		DELETE FROM ops_booking_receipt_dates WHERE booking_id = $1
+	`, opsBooking.BookingID)
+	if err != nil {
+		return fmt.Errorf("could not remove receipt dates of ops booking %s: %w", opsBooking.BookingID, err)
+	}
+
+	for _, date := range opsBooking.ReceiptIssueDates() {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO ops_booking_receipt_dates (booking_id, receipt_issue_date) VALUES ($1, $2)
+		`, opsBooking.BookingID, date)
+		if err != nil {
+			return fmt.Errorf("could not add receipt date of ops booking %s: %w", opsBooking.BookingID, err)
+		}
+	}
+
+	return nil
+}
+
+func (r OpsBookingsRepository) ByID(ctx context.Context, bookingID string) (entities.OpsBooking, error) {
+	var payload []byte
+	err := r.db.GetContext(ctx, &payload, `SELECT payload FROM ops_bookings WHERE booking_id = $1`, bookingID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.OpsBooking{}, entities.ErrOpsBookingNotFound
+	}
+	if err != nil {
+		return entities.OpsBooking{}, fmt.Errorf("could not get ops booking %s: %w", bookingID, err)
+	}
+
+	return unmarshalOpsBooking(payload)
+}
+
+// All returns a page of read models matching the filter.
+// Receipt dates of read models built before they were indexed are found after replaying the projection.
+func (r OpsBookingsRepository) All(ctx context.Context, filter entities.OpsBookingsFilter) ([]entities.OpsBooking, error) {
+	query := `SELECT ops_bookings.payload FROM ops_bookings`
+	args := []any{uuid.Nil.String()}
+	if filter.AfterBookingID != "" {
+		args[0] = filter.AfterBookingID
+	}
+
+	if filter.ReceiptIssueDate != nil {
+		// a booking has at most one row per date, so it's not repeated
+		query += ` JOIN ops_booking_receipt_dates ON ops_booking_receipt_dates.booking_id = ops_bookings.booking_id`
+	}
+
+	query += ` WHERE ops_bookings.booking_id > $1`
+
+	if filter.ReceiptIssueDate != nil {
+		args = append(args, filter.ReceiptIssueDate.Format(time.DateOnly))
+		query += fmt.Sprintf(` AND ops_booking_receipt_dates.receipt_issue_date = $%d`, len(args))
+	}
+
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(` ORDER BY ops_bookings.booking_id LIMIT $%d`, len(args))
+
+	var payloads [][]byte
+	err := r.db.SelectContext(ctx, &payloads, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not get ops bookings: %w", err)
+	}
+
+	opsBookings := make([]entities.OpsBooking, 0, len(payloads))
+	for _, payload := range payloads {
+		opsBooking, err := unmarshalOpsBooking(payload)
+		if err != nil {
+			return nil, err
+		}
+
+		opsBookings = append(opsBookings, opsBooking)
+	}
+
+	return opsBookings, nil
+}
+
+func unmarshalOpsBooking(payload []byte) (entities.OpsBooking, error) {
+	var opsBooking entities.OpsBooking
+	err := json.Unmarshal(payload, &opsBooking)
+	if err != nil {
+		return entities.OpsBooking{}, fmt.Errorf("could not unmarshal ops booking: %w", err)
+	}
+
+	if opsBooking.Tickets == nil {
+		opsBooking.Tickets = map[string]entities.OpsTicket{}
+	}
+
+	return opsBooking, nil
+}
@@ -0,0 +1,10 @@
+package db
+
+import (
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+func NewPostgresConnection(url string) (*sqlx.DB, error) {
+	return sqlx.Open("postgres", url)
+}
@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"tickets/entities"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type PromoCodesRepository struct {
+	db *sqlx.DB
+}
+
+func NewPromoCodesRepository(db *sqlx.DB) PromoCodesRepository {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return PromoCodesRepository{db: db}
+}
+
+type promoCodeRow struct {
+	entities.PromoCode
+	AmountOff []byte `db:"amount_off"`
+}
+
+func (r PromoCodesRepository) Add(ctx context.Context, promoCode entities.PromoCode) error {
+	// percentage discounts store JSON null
+	amountOff, err := json.Marshal(promoCode.AmountOff)
+	if err != nil {
+		return fmt.Errorf("could not marshal amount off: %w", err)
+	}
+
+	res, err := r.db.NamedExecContext(ctx, `
+		INSERT INTO promo_codes (code, percent_off, amount_off, valid_from, valid_until, max_uses, max_uses_per_customer, created_at)
+		VALUES (:code, :percent_off, :amount_off, :valid_from, :valid_until, :max_uses, :max_uses_per_customer, :created_at)
+		ON CONFLICT (code) DO NOTHING
+	`, promoCodeRow{PromoCode: promoCode, AmountOff: amountOff})
+	if err != nil {
+		return fmt.Errorf("could not add promo code %s: %w", promoCode.Code, err)
+	}
+
+	added, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("could not check if promo code %s was added: %w", promoCode.Code, err)
+	}
+	if added == 0 {
+		return entities.ErrPromoCodeExists
+	}
+
+	return nil
+}
+
+// ByCode returns the promo code with the number of bookings currently using it.
+func (r PromoCodesRepository) ByCode(ctx context.Context, code string) (entities.PromoCode, error) {
+	var row promoCodeRow
+	err := r.db.GetContext(ctx, &row, `
+		SELECT p.*, (SELECT COUNT(*) FROM bookings b WHERE b.promo_code = p.code) AS uses
+		FROM promo_codes p WHERE p.code = $1
+	`, code)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.PromoCode{}, entities.ErrPromoCodeNotFound
+	}
+	if err != nil {
+		return entities.PromoCode{}, fmt.Errorf("could not get promo code %s: %w", code, err)
+	}
+
+	promoCode := row.PromoCode
+	if len(row.AmountOff) > 0 {
+		err = json.Unmarshal(row.AmountOff, &promoCode.AmountOff)
+		if err != nil {
+			return entities.PromoCode{}, fmt.Errorf("could not unmarshal amount off of promo code %s: %w", code, err)
+		}
+	}
+
+	return promoCode, nil
+}
@@ -0,0 +1,138 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// schema is applied on every start. Tables keep the columns they were created with,
+// columns added later are added with ALTER TABLE, so databases created by earlier versions get them too.
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS shows (
+		show_id UUID PRIMARY KEY,
+		title VARCHAR(255) NOT NULL,
+		venue VARCHAR(255) NOT NULL,
+		start_time TIMESTAMPTZ NOT NULL,
+		number_of_tickets INT NOT NULL,
+		external_provider BOOLEAN NOT NULL DEFAULT FALSE
+	)`,
+	`ALTER TABLE shows ADD COLUMN IF NOT EXISTS dead_nation_id UUID`,
+	`ALTER TABLE shows ADD COLUMN IF NOT EXISTS seat_map JSONB`,
+	`ALTER TABLE shows ADD COLUMN IF NOT EXISTS refund_policy JSONB`,
+	`ALTER TABLE shows ADD COLUMN IF NOT EXISTS ticket_price JSONB`,
+	`CREATE TABLE IF NOT EXISTS bookings (
+		booking_id UUID PRIMARY KEY,
+		show_id UUID NOT NULL REFERENCES shows(show_id),
+		number_of_tickets INT NOT NULL,
+		customer_email VARCHAR(255) NOT NULL
+	)`,
+	`ALTER TABLE bookings ADD COLUMN IF NOT EXISTS vip BOOLEAN NOT NULL DEFAULT FALSE`,
+	`ALTER TABLE bookings ADD COLUMN IF NOT EXISTS taxi_booking_id UUID`,
+	`ALTER TABLE bookings ADD COLUMN IF NOT EXISTS taxi_cancelled BOOLEAN NOT NULL DEFAULT FALSE`,
+	`ALTER TABLE bookings ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ`,
+	`ALTER TABLE bookings ADD COLUMN IF NOT EXISTS promo_code VARCHAR(255)`,
+	`ALTER TABLE bookings ADD COLUMN IF NOT EXISTS original_price JSONB`,
+	`ALTER TABLE bookings ADD COLUMN IF NOT EXISTS price JSONB`,
+	`CREATE TABLE IF NOT EXISTS booked_seats (
+		show_id UUID NOT NULL,
+		section VARCHAR(255) NOT NULL,
+		seat_row VARCHAR(255) NOT NULL,
+		seat_number INT NOT NULL,
+		booking_id UUID NOT NULL,
+		ticket_id UUID,
+		PRIMARY KEY (show_id, section, seat_row, seat_number)
+	)`,
+	`CREATE TABLE IF NOT EXISTS released_tickets (
+		ticket_id UUID PRIMARY KEY,
+		booking_id UUID NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS waitlist (
+		entry_id UUID PRIMARY KEY,
+		show_id UUID NOT NULL,
+		customer_email VARCHAR(255) NOT NULL,
+		number_of_tickets INT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL,
+		offered_at TIMESTAMPTZ
+	)`,
+	`ALTER TABLE waitlist ADD COLUMN IF NOT EXISTS claimed_at TIMESTAMPTZ`,
+	`CREATE TABLE IF NOT EXISTS promo_codes (
+		code VARCHAR(255) PRIMARY KEY,
+		percent_off INT NOT NULL DEFAULT 0,
+		amount_off JSONB,
+		valid_from TIMESTAMPTZ,
+		valid_until TIMESTAMPTZ,
+		max_uses INT,
+		max_uses_per_customer INT,
+		created_at TIMESTAMPTZ NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS vip_bundles (
+		vip_bundle_id UUID PRIMARY KEY,
+		booking_id UUID NOT NULL UNIQUE,
+		payload JSONB NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS ops_bookings (
+		booking_id UUID PRIMARY KEY,
+		payload JSONB NOT NULL
+	)`,
+	// receipt dates are nested in the tickets of the ops_bookings payload, so they're indexed here
+	`CREATE TABLE IF NOT EXISTS ops_booking_receipt_dates (
+		booking_id UUID NOT NULL,
+		receipt_issue_date DATE NOT NULL,
+		PRIMARY KEY (receipt_issue_date, booking_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS webhooks (
+		webhook_id UUID PRIMARY KEY,
+		url TEXT NOT NULL,
+		event_types JSONB NOT NULL,
+		secret TEXT NOT NULL,
+		disabled BOOLEAN NOT NULL DEFAULT FALSE,
+		consecutive_failures INT NOT NULL DEFAULT 0,
+		created_at TIMESTAMPTZ NOT NULL
+	)`,
+	// webhooks registered before they were scoped to shows have none, so nothing is delivered to them
+	`ALTER TABLE webhooks ADD COLUMN IF NOT EXISTS show_ids JSONB`,
+	`CREATE TABLE IF NOT EXISTS webhook_attempts (
+		attempt_id UUID PRIMARY KEY,
+		webhook_id UUID NOT NULL REFERENCES webhooks(webhook_id),
+		event_id UUID NOT NULL,
+		event_name VARCHAR(255) NOT NULL,
+		status_code INT NOT NULL,
+		error TEXT NOT NULL,
+		succeeded BOOLEAN NOT NULL,
+		attempted_at TIMESTAMPTZ NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS show_reminders (
+		ticket_id UUID PRIMARY KEY,
+		canceled BOOLEAN NOT NULL DEFAULT FALSE,
+		sent_at TIMESTAMPTZ
+	)`,
+	`ALTER TABLE show_reminders ADD COLUMN IF NOT EXISTS claimed_until TIMESTAMPTZ`,
+	`CREATE TABLE IF NOT EXISTS delayed_messages (
+		message_id UUID PRIMARY KEY,
+		topic VARCHAR(255) NOT NULL,
+		payload BYTEA NOT NULL,
+		metadata JSONB NOT NULL,
+		publish_at TIMESTAMPTZ NOT NULL
+	)`,
+	`ALTER TABLE delayed_messages ADD COLUMN IF NOT EXISTS claimed_until TIMESTAMPTZ`,
+	`CREATE TABLE IF NOT EXISTS events (
+		event_id UUID PRIMARY KEY,
+		event_name VARCHAR(255) NOT NULL,
+		published_at TIMESTAMPTZ NOT NULL,
+		correlation_id VARCHAR(255) NOT NULL,
+		payload JSONB NOT NULL
+	)`,
+}
+
+func InitializeDatabaseSchema(ctx context.Context, db *sqlx.DB) error {
+	for _, statement := range schema {
+		_, err := db.ExecContext(ctx, statement)
+		if err != nil {
+			return fmt.Errorf("failed to initialize database schema: %w", err)
+		}
+	}
+
+	return nil
+}
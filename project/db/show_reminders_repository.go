@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"tickets/entities"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type ShowRemindersRepository struct {
+	db *sqlx.DB
+}
+
+func NewShowRemindersRepository(db *sqlx.DB) ShowRemindersRepository {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return ShowRemindersRepository{db: db}
+}
+
+// Update locks the reminder of the ticket until updateFn returns and stores its changes.
+// The reminder is created when missing, as the ticket may be canceled before its reminder is scheduled.
+func (r ShowRemindersRepository) Update(
+	ctx context.Context,
+	ticketID string,
+	updateFn func(ctx context.Context, reminder *entities.ShowReminder) error,
+) error {
+	return updateInTx(ctx, r.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO show_reminders (ticket_id)
+			VALUES ($1)
+			ON CONFLICT (ticket_id) DO NOTHING
+		`, ticketID)
+		if err != nil {
+			return fmt.Errorf("could not add show reminder of ticket %s: %w", ticketID, err)
+		}
+
+		var reminder entities.ShowReminder
+		err = tx.GetContext(ctx, &reminder, `
+			SELECT ticket_id, canceled, sent_at, claimed_until FROM show_reminders WHERE ticket_id = $1 FOR UPDATE
+		`, ticketID)
+		if err != nil {
+			return fmt.Errorf("could not get show reminder of ticket %s: %w", ticketID, err)
+		}
+
+		err = updateFn(ctx, &reminder)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.NamedExecContext(ctx, `
+			UPDATE show_reminders
+			SET canceled = :canceled, sent_at = :sent_at, claimed_until = :claimed_until
+			WHERE ticket_id = :ticket_id
+		`, reminder)
+		if err != nil {
+			return fmt.Errorf("could not update show reminder of ticket %s: %w", ticketID, err)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,138 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"tickets/entities"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type ShowsRepository struct {
+	db *sqlx.DB
+}
+
+func NewShowsRepository(db *sqlx.DB) ShowsRepository {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return ShowsRepository{db: db}
+}
+
+type showRow struct {
+	entities.Show
+	SeatMap      []byte `db:"seat_map"`
+	RefundPolicy []byte `db:"refund_policy"`
+	TicketPrice  []byte `db:"ticket_price"`
+}
+
+// AddShow stores the show along with the outbox messages about it, adding a show that exists is a no-op.
+func (r ShowsRepository) AddShow(ctx context.Context, show entities.Show, outbox ...entities.DelayedMessage) error {
+	// shows without a seat map store JSON null
+	seatMap, err := json.Marshal(show.SeatMap)
+	if err != nil {
+		return fmt.Errorf("could not marshal seat map: %w", err)
+	}
+
+	refundPolicy, err := json.Marshal(show.RefundPolicy)
+	if err != nil {
+		return fmt.Errorf("could not marshal refund policy: %w", err)
+	}
+
+	ticketPrice, err := json.Marshal(show.TicketPrice)
+	if err != nil {
+		return fmt.Errorf("could not marshal ticket price: %w", err)
+	}
+
+	return updateInTx(ctx, r.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		result, err := tx.NamedExecContext(ctx, `
+			INSERT INTO shows (
+				show_id, title, venue, start_time, number_of_tickets, external_provider, dead_nation_id,
+				seat_map, refund_policy, ticket_price
+			)
+			VALUES (
+				:show_id, :title, :venue, :start_time, :number_of_tickets, :external_provider, :dead_nation_id,
+				:seat_map, :refund_policy, :ticket_price
+			)
+			ON CONFLICT (show_id) DO NOTHING
+		`, showRow{Show: show, SeatMap: seatMap, RefundPolicy: refundPolicy, TicketPrice: ticketPrice})
+		if err != nil {
+			return fmt.Errorf("could not add show %s: %w", show.ShowID, err)
+		}
+
+		added, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("could not add show %s: %w", show.ShowID, err)
+		}
+		if added == 0 {
+			// the outbox messages were stored with the show
+			return nil
+		}
+
+		return addDelayedMessages(ctx, tx, outbox)
+	})
+}
+
+func (r ShowsRepository) AllShows(ctx context.Context) ([]entities.Show, error) {
+	var rows []showRow
+	err := r.db.SelectContext(ctx, &rows, `SELECT * FROM shows ORDER BY start_time`)
+	if err != nil {
+		return nil, fmt.Errorf("could not get shows: %w", err)
+	}
+
+	shows := make([]entities.Show, 0, len(rows))
+	for _, row := range rows {
+		show, err := row.show()
+		if err != nil {
+			return nil, err
+		}
+
+		shows = append(shows, show)
+	}
+
+	return shows, nil
+}
+
+func (r ShowsRepository) ShowByID(ctx context.Context, showID string) (entities.Show, error) {
+	var row showRow
+	err := r.db.GetContext(ctx, &row, `SELECT * FROM shows WHERE show_id = $1`, showID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.Show{}, entities.ErrShowNotFound
+	}
+	if err != nil {
+		return entities.Show{}, fmt.Errorf("could not get show %s: %w", showID, err)
+	}
+
+	return row.show()
+}
+
+func (r showRow) show() (entities.Show, error) {
+	show := r.Show
+
+	if len(r.SeatMap) > 0 {
+		err := json.Unmarshal(r.SeatMap, &show.SeatMap)
+		if err != nil {
+			return entities.Show{}, fmt.Errorf("could not unmarshal seat map of show %s: %w", show.ShowID, err)
+		}
+	}
+
+	if len(r.RefundPolicy) > 0 {
+		err := json.Unmarshal(r.RefundPolicy, &show.RefundPolicy)
+		if err != nil {
+			return entities.Show{}, fmt.Errorf("could not unmarshal refund policy of show %s: %w", show.ShowID, err)
+		}
+	}
+
+	if len(r.TicketPrice) > 0 {
+		err := json.Unmarshal(r.TicketPrice, &show.TicketPrice)
+		if err != nil {
+			return entities.Show{}, fmt.Errorf("could not unmarshal ticket price of show %s: %w", show.ShowID, err)
+		}
+	}
+
+	return show, nil
+}
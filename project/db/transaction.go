@@ -0,0 +1,36 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func updateInTx(
+	ctx context.Context,
+	db *sqlx.DB,
+	fn func(ctx context.Context, tx *sqlx.Tx) error,
+) (err error) {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				err = errors.Join(err, fmt.Errorf("could not rollback transaction: %w", rollbackErr))
+			}
+			return
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			err = fmt.Errorf("could not commit transaction: %w", err)
+		}
+	}()
+
+	return fn(ctx, tx)
+}
@@ -0,0 +1,114 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"tickets/entities"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type VipBundlesRepository struct {
+	db *sqlx.DB
+}
+
+func NewVipBundlesRepository(db *sqlx.DB) VipBundlesRepository {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return VipBundlesRepository{db: db}
+}
+
+func (r VipBundlesRepository) Add(ctx context.Context, vipBundle entities.VipBundle, outbox ...entities.DelayedMessage) error {
+	payload, err := json.Marshal(vipBundle)
+	if err != nil {
+		return fmt.Errorf("could not marshal vip bundle: %w", err)
+	}
+
+	return updateInTx(ctx, r.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO vip_bundles (vip_bundle_id, booking_id, payload)
+			VALUES ($1, $2, $3)
+		`, vipBundle.VipBundleID, vipBundle.BookingID, payload)
+		if err != nil {
+			return fmt.Errorf("could not add vip bundle %s: %w", vipBundle.VipBundleID, err)
+		}
+
+		return addDelayedMessages(ctx, tx, outbox)
+	})
+}
+
+func (r VipBundlesRepository) ByID(ctx context.Context, vipBundleID string) (entities.VipBundle, error) {
+	var payload []byte
+	err := r.db.GetContext(ctx, &payload, `SELECT payload FROM vip_bundles WHERE vip_bundle_id = $1`, vipBundleID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.VipBundle{}, entities.ErrVipBundleNotFound
+	}
+	if err != nil {
+		return entities.VipBundle{}, fmt.Errorf("could not get vip bundle %s: %w", vipBundleID, err)
+	}
+
+	return unmarshalVipBundle(payload)
+}
+
+// UpdateByBookingID locks the bundle of the booking until updateFn returns and stores its changes,
+// together with the outbox messages updateFn returns. Nothing is stored when updateFn returns an error.
+func (r VipBundlesRepository) UpdateByBookingID(
+	ctx context.Context,
+	bookingID string,
+	updateFn func(ctx context.Context, vipBundle *entities.VipBundle) ([]entities.DelayedMessage, error),
+) error {
+	return updateInTx(ctx, r.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		var payload []byte
+		err := tx.GetContext(ctx, &payload, `
+			SELECT payload FROM vip_bundles WHERE booking_id = $1 FOR UPDATE
+		`, bookingID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.ErrVipBundleNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("could not get vip bundle of booking %s: %w", bookingID, err)
+		}
+
+		vipBundle, err := unmarshalVipBundle(payload)
+		if err != nil {
+			return err
+		}
+
+		outbox, err := updateFn(ctx, &vipBundle)
+		if err != nil {
+			return err
+		}
+
+		vipBundle.UpdatedAt = time.Now().UTC()
+
+		payload, err = json.Marshal(vipBundle)
+		if err != nil {
+			return fmt.Errorf("could not marshal vip bundle: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE vip_bundles SET payload = $1 WHERE vip_bundle_id = $2
+		`, payload, vipBundle.VipBundleID)
+		if err != nil {
+			return fmt.Errorf("could not update vip bundle %s: %w", vipBundle.VipBundleID, err)
+		}
+
+		return addDelayedMessages(ctx, tx, outbox)
+	})
+}
+
+func unmarshalVipBundle(payload []byte) (entities.VipBundle, error) {
+	var vipBundle entities.VipBundle
+	err := json.Unmarshal(payload, &vipBundle)
+	if err != nil {
+		return entities.VipBundle{}, fmt.Errorf("could not unmarshal vip bundle: %w", err)
+	}
+
+	return vipBundle, nil
+}
@@ -0,0 +1,215 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"tickets/entities"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type WaitlistRepository struct {
+	db *sqlx.DB
+}
+
+func NewWaitlistRepository(db *sqlx.DB) WaitlistRepository {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return WaitlistRepository{db: db}
+}
+
+// Add returns entities.ErrShowNotSoldOut when the entry's tickets can be booked right away.
+// The show is locked like in OfferNext, so tickets freed concurrently are offered to the entry.
+func (r WaitlistRepository) Add(ctx context.Context, entry entities.WaitlistEntry) error {
+	return updateInTx(ctx, r.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		show, err := lockShow(ctx, tx, entry.ShowID)
+		if err != nil {
+			return err
+		}
+
+		booked, err := bookedTickets(ctx, tx, show.ShowID)
+		if err != nil {
+			return err
+		}
+		if booked+entry.NumberOfTickets <= show.NumberOfTickets {
+			return entities.ErrShowNotSoldOut
+		}
+
+		_, err = tx.NamedExecContext(ctx, `
+			INSERT INTO waitlist (entry_id, show_id, customer_email, number_of_tickets, created_at)
+			VALUES (:entry_id, :show_id, :customer_email, :number_of_tickets, :created_at)
+		`, entry)
+		if err != nil {
+			return fmt.Errorf("could not add waitlist entry %s: %w", entry.EntryID, err)
+		}
+
+		return nil
+	})
+}
+
+func (r WaitlistRepository) ByID(ctx context.Context, entryID string) (entities.WaitlistEntry, error) {
+	var entry entities.WaitlistEntry
+	err := r.db.GetContext(ctx, &entry, `SELECT * FROM waitlist WHERE entry_id = $1`, entryID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.WaitlistEntry{}, entities.ErrWaitlistEntryNotFound
+	}
+	if err != nil {
+		return entities.WaitlistEntry{}, fmt.Errorf("could not get waitlist entry %s: %w", entryID, err)
+	}
+
+	return entry, nil
+}
+
+// OfferNext books the tickets of the show's first waiting entry for its customer until expiresAt,
+// and stores the outbox message returned by outboxFn with the booking. It returns false when nobody is waiting,
+// or when the first entry doesn't fit in the tickets left, as the customers are offered tickets in order.
+// The show is locked until the offer is made, so the offered tickets can't be booked by anyone else.
+func (r WaitlistRepository) OfferNext(
+	ctx context.Context,
+	showID string,
+	offeredAt time.Time,
+	expiresAt time.Time,
+	outboxFn func(ctx context.Context, entry entities.WaitlistEntry) (entities.DelayedMessage, error),
+) (bool, error) {
+	offered := false
+
+	err := updateInTx(ctx, r.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		offered = false
+
+		show, err := lockShow(ctx, tx, showID)
+		if err != nil {
+			return err
+		}
+
+		var entry entities.WaitlistEntry
+		err = tx.GetContext(ctx, &entry, `
+			SELECT * FROM waitlist
+			WHERE show_id = $1 AND offered_at IS NULL
+			ORDER BY created_at, entry_id
+			LIMIT 1
+			FOR UPDATE
+		`, showID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("could not get waitlist of show %s: %w", showID, err)
+		}
+
+		// the booking has the entry's ID, so the offer is made once
+		_, err = addBooking(ctx, tx, show, entities.Booking{
+			BookingID:       entry.EntryID,
+			ShowID:          entry.ShowID,
+			NumberOfTickets: entry.NumberOfTickets,
+			CustomerEmail:   entry.CustomerEmail,
+			ExpiresAt:       &expiresAt,
+		})
+		if errors.Is(err, entities.ErrNotEnoughTickets) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		res, err := tx.ExecContext(ctx, `
+			UPDATE waitlist SET offered_at = $1 WHERE entry_id = $2 AND offered_at IS NULL
+		`, offeredAt, entry.EntryID)
+		if err != nil {
+			return fmt.Errorf("could not mark waitlist entry %s as offered: %w", entry.EntryID, err)
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("could not mark waitlist entry %s as offered: %w", entry.EntryID, err)
+		}
+		if affected == 0 {
+			return fmt.Errorf("waitlist entry %s was offered concurrently", entry.EntryID)
+		}
+
+		outboxMessage, err := outboxFn(ctx, entry)
+		if err != nil {
+			return err
+		}
+
+		err = addDelayedMessages(ctx, tx, []entities.DelayedMessage{outboxMessage})
+		if err != nil {
+			return err
+		}
+
+		offered = true
+
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return offered, nil
+}
+
+// Claim accepts the offer made to the entry, the outbox messages are stored with the claim.
+// Claiming an offer again is a no-op, offers whose bookings expired can't be claimed.
+func (r WaitlistRepository) Claim(
+	ctx context.Context,
+	entryID string,
+	now time.Time,
+	outbox ...entities.DelayedMessage,
+) error {
+	return updateInTx(ctx, r.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		var entry entities.WaitlistEntry
+		err := tx.GetContext(ctx, &entry, `
+			SELECT * FROM waitlist WHERE entry_id = $1 FOR UPDATE
+		`, entryID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.ErrWaitlistEntryNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("could not get waitlist entry %s: %w", entryID, err)
+		}
+
+		if entry.OfferedAt == nil {
+			return entities.ErrWaitlistOfferNotMade
+		}
+		if entry.ClaimedAt != nil {
+			return nil
+		}
+
+		// expired bookings are removed
+		var expiresAt *time.Time
+		err = tx.GetContext(ctx, &expiresAt, `
+			SELECT expires_at FROM bookings WHERE booking_id = $1 FOR UPDATE
+		`, entryID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.ErrWaitlistOfferExpired
+		}
+		if err != nil {
+			return fmt.Errorf("could not get booking %s: %w", entryID, err)
+		}
+		if expiresAt != nil && !expiresAt.After(now) {
+			return entities.ErrWaitlistOfferExpired
+		}
+
+		res, err := tx.ExecContext(ctx, `
+			UPDATE waitlist SET claimed_at = $1 WHERE entry_id = $2 AND claimed_at IS NULL
+		`, now, entryID)
+		if err != nil {
+			return fmt.Errorf("could not claim waitlist entry %s: %w", entryID, err)
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("could not claim waitlist entry %s: %w", entryID, err)
+		}
+		if affected == 0 {
+			// claimed concurrently
+			return nil
+		}
+
+		return addDelayedMessages(ctx, tx, outbox)
+	})
+}
@@ -0,0 +1,163 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"tickets/entities"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type WebhooksRepository struct {
+	db *sqlx.DB
+}
+
+func NewWebhooksRepository(db *sqlx.DB) WebhooksRepository {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return WebhooksRepository{db: db}
+}
+
+type webhookRow struct {
+	entities.Webhook
+	EventTypes []byte `db:"event_types"`
+	ShowIDs    []byte `db:"show_ids"`
+}
+
+func (r WebhooksRepository) Add(ctx context.Context, webhook entities.Webhook) error {
+	eventTypes, err := json.Marshal(webhook.EventTypes)
+	if err != nil {
+		return fmt.Errorf("could not marshal event types: %w", err)
+	}
+
+	showIDs, err := json.Marshal(webhook.ShowIDs)
+	if err != nil {
+		return fmt.Errorf("could not marshal show ids: %w", err)
+	}
+
+	_, err = r.db.NamedExecContext(ctx, `
+		INSERT INTO webhooks (webhook_id, url, event_types, show_ids, secret, disabled, consecutive_failures, created_at)
+		VALUES (:webhook_id, :url, :event_types, :show_ids, :secret, :disabled, :consecutive_failures, :created_at)
+	`, webhookRow{Webhook: webhook, EventTypes: eventTypes, ShowIDs: showIDs})
+	if err != nil {
+		return fmt.Errorf("could not add webhook %s: %w", webhook.WebhookID, err)
+	}
+
+	return nil
+}
+
+func (r WebhooksRepository) ByID(ctx context.Context, webhookID string) (entities.Webhook, error) {
+	var row webhookRow
+	err := r.db.GetContext(ctx, &row, `SELECT * FROM webhooks WHERE webhook_id = $1`, webhookID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.Webhook{}, entities.ErrWebhookNotFound
+	}
+	if err != nil {
+		return entities.Webhook{}, fmt.Errorf("could not get webhook %s: %w", webhookID, err)
+	}
+
+	return row.webhook()
+}
+
+func (r WebhooksRepository) Enabled(ctx context.Context) ([]entities.Webhook, error) {
+	var rows []webhookRow
+	err := r.db.SelectContext(ctx, &rows, `SELECT * FROM webhooks WHERE disabled = FALSE ORDER BY created_at`)
+	if err != nil {
+		return nil, fmt.Errorf("could not get enabled webhooks: %w", err)
+	}
+
+	webhooks := make([]entities.Webhook, 0, len(rows))
+	for _, row := range rows {
+		webhook, err := row.webhook()
+		if err != nil {
+			return nil, err
+		}
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, nil
+}
+
+// RecordAttempt stores the delivery attempt. A successful attempt resets the endpoint's failures,
+// while giving up on an event counts a failure and disables the endpoint after disableAfterFailures of them in a row.
+func (r WebhooksRepository) RecordAttempt(
+	ctx context.Context,
+	attempt entities.WebhookAttempt,
+	gaveUp bool,
+	disableAfterFailures int,
+) error {
+	return updateInTx(ctx, r.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		_, err := tx.NamedExecContext(ctx, `
+			INSERT INTO webhook_attempts (attempt_id, webhook_id, event_id, event_name, status_code, error, succeeded, attempted_at)
+			VALUES (:attempt_id, :webhook_id, :event_id, :event_name, :status_code, :error, :succeeded, :attempted_at)
+		`, attempt)
+		if err != nil {
+			return fmt.Errorf("could not add attempt of webhook %s: %w", attempt.WebhookID, err)
+		}
+
+		if attempt.Succeeded {
+			_, err = tx.ExecContext(ctx, `
+				UPDATE webhooks SET consecutive_failures = 0 WHERE webhook_id = $1
+			`, attempt.WebhookID)
+		} else if gaveUp {
+			_, err = tx.ExecContext(ctx, `
+				UPDATE webhooks
+				SET disabled = disabled OR consecutive_failures + 1 >= $1,
+					consecutive_failures = consecutive_failures + 1
+				WHERE webhook_id = $2
+			`, disableAfterFailures, attempt.WebhookID)
+		}
+		if err != nil {
+			return fmt.Errorf("could not update failures of webhook %s: %w", attempt.WebhookID, err)
+		}
+
+		return nil
+	})
+}
+
+func (r WebhooksRepository) Attempts(ctx context.Context, webhookID string, eventID string) ([]entities.WebhookAttempt, error) {
+	var attempts []entities.WebhookAttempt
+	err := r.db.SelectContext(ctx, &attempts, `
+		SELECT * FROM webhook_attempts WHERE webhook_id = $1 AND event_id = $2 ORDER BY attempted_at
+	`, webhookID, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get attempts of webhook %s: %w", webhookID, err)
+	}
+
+	return attempts, nil
+}
+
+func (r WebhooksRepository) AttemptsByWebhookID(ctx context.Context, webhookID string) ([]entities.WebhookAttempt, error) {
+	var attempts []entities.WebhookAttempt
+	err := r.db.SelectContext(ctx, &attempts, `
+		SELECT * FROM webhook_attempts WHERE webhook_id = $1 ORDER BY attempted_at
+	`, webhookID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get attempts of webhook %s: %w", webhookID, err)
+	}
+
+	return attempts, nil
+}
+
+func (r webhookRow) webhook() (entities.Webhook, error) {
+	webhook := r.Webhook
+	err := json.Unmarshal(r.EventTypes, &webhook.EventTypes)
+	if err != nil {
+		return entities.Webhook{}, fmt.Errorf("could not unmarshal event types of webhook %s: %w", webhook.WebhookID, err)
+	}
+
+	if r.ShowIDs != nil {
+		err = json.Unmarshal(r.ShowIDs, &webhook.ShowIDs)
+		if err != nil {
+			return entities.Webhook{}, fmt.Errorf("could not unmarshal show ids of webhook %s: %w", webhook.WebhookID, err)
+		}
+	}
+
+	return webhook, nil
+}
@@ -0,0 +1,33 @@
+package entities
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrShowNotFound     = errors.New("show not found")
+	ErrBookingNotFound  = errors.New("booking not found")
+	ErrNotEnoughTickets = errors.New("not enough tickets left for the show")
+	ErrTaxiCancelled    = errors.New("taxi of the booking was cancelled")
+	ErrBookingExpired   = errors.New("booking expired")
+)
+
+type Booking struct {
+	BookingID       string `json:"booking_id" db:"booking_id"`
+	ShowID          string `json:"show_id" db:"show_id"`
+	NumberOfTickets int    `json:"number_of_tickets" db:"number_of_tickets"`
+	CustomerEmail   string `json:"customer_email" db:"customer_email"`
+	VIP             bool   `json:"vip" db:"vip"`
+	// TaxiBookingID is set once the taxi of a VIP booking is booked.
+	TaxiBookingID *string `json:"taxi_booking_id,omitempty" db:"taxi_booking_id"`
+	TaxiCancelled bool    `json:"taxi_cancelled" db:"taxi_cancelled"`
+	// ExpiresAt is when the booking's tickets are released unless one of them is confirmed before.
+	ExpiresAt *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	Seats     []Seat     `json:"seats,omitempty" db:"-"`
+	// PromoCode discounts the booking's tickets, OriginalPrice and Price are the price of a ticket
+	// before and after the discount, as of the time the booking was made.
+	PromoCode     *string `json:"promo_code,omitempty" db:"promo_code"`
+	OriginalPrice *Money  `json:"original_price,omitempty" db:"-"`
+	Price         *Money  `json:"price,omitempty" db:"-"`
+}
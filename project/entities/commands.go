@@ -1,7 +1,67 @@
 package entities
 
+import "encoding/json"
+
 type RefundTicket struct {
 	Header EventHeader `json:"header"`
 
 	TicketID string `json:"ticket_id"`
 }
+
+type BookTaxi struct {
+	Header EventHeader `json:"header"`
+
+	BookingID          string `json:"booking_id"`
+	CustomerEmail      string `json:"customer_email"`
+	NumberOfPassengers int    `json:"number_of_passengers"`
+}
+
+type CancelTaxiBooking struct {
+	Header EventHeader `json:"header"`
+
+	BookingID string `json:"booking_id"`
+	// TaxiBookingID is the taxi to cancel when the booking was removed, for example when it expired.
+	TaxiBookingID string `json:"taxi_booking_id,omitempty"`
+}
+
+type BookShowTickets struct {
+	Header EventHeader `json:"header"`
+
+	BookingID       string `json:"booking_id"`
+	ShowID          string `json:"show_id"`
+	NumberOfTickets int    `json:"number_of_tickets"`
+	CustomerEmail   string `json:"customer_email"`
+}
+
+type CancelBooking struct {
+	Header EventHeader `json:"header"`
+
+	BookingID string `json:"booking_id"`
+	ShowID    string `json:"show_id"`
+}
+
+type BookFlight struct {
+	Header EventHeader `json:"header"`
+
+	BookingID      string   `json:"booking_id"`
+	FlightID       string   `json:"flight_id"`
+	CustomerEmail  string   `json:"customer_email"`
+	PassengerNames []string `json:"passenger_names"`
+}
+
+type CancelFlightTickets struct {
+	Header EventHeader `json:"header"`
+
+	BookingID       string   `json:"booking_id"`
+	FlightID        string   `json:"flight_id"`
+	FlightTicketIDs []string `json:"flight_ticket_ids"`
+}
+
+type DeliverWebhook struct {
+	Header EventHeader `json:"header"`
+
+	WebhookID string          `json:"webhook_id"`
+	EventID   string          `json:"event_id"`
+	EventName string          `json:"event_name"`
+	Payload   json.RawMessage `json:"payload"`
+}
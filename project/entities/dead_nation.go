@@ -0,0 +1,8 @@
+package entities
+
+type DeadNationBooking struct {
+	BookingID         string
+	DeadNationEventID string
+	NumberOfTickets   int
+	CustomerEmail     string
+}
@@ -0,0 +1,12 @@
+package entities
+
+import "time"
+
+// DelayedMessage is a marshaled message waiting in storage until it's due to be published.
+type DelayedMessage struct {
+	MessageID string            `db:"message_id"`
+	Topic     string            `db:"topic"`
+	Payload   []byte            `db:"payload"`
+	Metadata  map[string]string `db:"-"`
+	PublishAt time.Time         `db:"publish_at"`
+}
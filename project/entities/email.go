@@ -0,0 +1,7 @@
+package entities
+
+type Email struct {
+	To      string
+	Subject string
+	Body    string
+}
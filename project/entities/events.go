@@ -18,16 +18,168 @@ func NewEventHeader() EventHeader {
 	}
 }
 
+func (h EventHeader) PublishedAtTime() (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, h.PublishedAt)
+}
+
 type TicketBookingConfirmed struct {
 	Header        EventHeader `json:"header"`
 	TicketID      string      `json:"ticket_id"`
+	BookingID     string      `json:"booking_id"`
 	CustomerEmail string      `json:"customer_email"`
-	Price         Money       `json:"price"`
+	Locale        string      `json:"locale"`
+	// Price is what the customer pays, after the discount of the booking's promo code.
+	Price         Money  `json:"price"`
+	OriginalPrice Money  `json:"original_price"`
+	PromoCode     string `json:"promo_code"`
+	// Seat is assigned to tickets of shows with a seat map.
+	Seat *Seat `json:"seat"`
 }
 
 type TicketBookingCanceled struct {
 	Header        EventHeader `json:"header"`
 	TicketID      string      `json:"ticket_id"`
+	BookingID     string      `json:"booking_id"`
 	CustomerEmail string      `json:"customer_email"`
+	Locale        string      `json:"locale"`
 	Price         Money       `json:"price"`
 }
+
+// TicketRefundCalculated follows TicketBookingCanceled with the amount refunded by the show's refund policy.
+type TicketRefundCalculated struct {
+	Header        EventHeader `json:"header"`
+	TicketID      string      `json:"ticket_id"`
+	BookingID     string      `json:"booking_id"`
+	CustomerEmail string      `json:"customer_email"`
+	Locale        string      `json:"locale"`
+	Price         Money       `json:"price"`
+	RefundPercent int         `json:"refund_percent"`
+	Refund        Money       `json:"refund"`
+}
+
+type ShowCreated struct {
+	Header           EventHeader  `json:"header"`
+	ShowID           string       `json:"show_id"`
+	Title            string       `json:"title"`
+	Venue            string       `json:"venue"`
+	StartTime        time.Time    `json:"start_time"`
+	NumberOfTickets  int          `json:"number_of_tickets"`
+	ExternalProvider bool         `json:"external_provider"`
+	DeadNationID     *string      `json:"dead_nation_id,omitempty"`
+	SeatMap          *SeatMap     `json:"seat_map,omitempty"`
+	RefundPolicy     RefundPolicy `json:"refund_policy,omitempty"`
+	TicketPrice      *Money       `json:"ticket_price,omitempty"`
+}
+
+type BookingMade struct {
+	Header          EventHeader `json:"header"`
+	BookingID       string      `json:"booking_id"`
+	ShowID          string      `json:"show_id"`
+	NumberOfTickets int         `json:"number_of_tickets"`
+	CustomerEmail   string      `json:"customer_email"`
+	VIP             bool        `json:"vip"`
+}
+
+type TicketPrinted struct {
+	Header    EventHeader `json:"header"`
+	TicketID  string      `json:"ticket_id"`
+	BookingID string      `json:"booking_id"`
+	FileName  string      `json:"file_name"`
+}
+
+type TicketReceiptIssued struct {
+	Header        EventHeader `json:"header"`
+	TicketID      string      `json:"ticket_id"`
+	BookingID     string      `json:"booking_id"`
+	ReceiptNumber string      `json:"receipt_number"`
+	IssuedAt      time.Time   `json:"issued_at"`
+}
+
+type ReservationExpired struct {
+	Header          EventHeader `json:"header"`
+	BookingID       string      `json:"booking_id"`
+	ShowID          string      `json:"show_id"`
+	NumberOfTickets int         `json:"number_of_tickets"`
+	CustomerEmail   string      `json:"customer_email"`
+	VIP             bool        `json:"vip"`
+	// TaxiBookingID is the taxi booked for the VIP booking before it expired.
+	TaxiBookingID *string   `json:"taxi_booking_id,omitempty"`
+	ExpiredAt     time.Time `json:"expired_at"`
+}
+
+type WaitlistOfferMade struct {
+	Header          EventHeader `json:"header"`
+	EntryID         string      `json:"entry_id"`
+	BookingID       string      `json:"booking_id"`
+	ShowID          string      `json:"show_id"`
+	CustomerEmail   string      `json:"customer_email"`
+	NumberOfTickets int         `json:"number_of_tickets"`
+	ExpiresAt       time.Time   `json:"expires_at"`
+}
+
+type ShowReminderDue struct {
+	Header        EventHeader `json:"header"`
+	TicketID      string      `json:"ticket_id"`
+	BookingID     string      `json:"booking_id"`
+	CustomerEmail string      `json:"customer_email"`
+	Locale        string      `json:"locale"`
+	ShowID        string      `json:"show_id"`
+	Title         string      `json:"title"`
+	Venue         string      `json:"venue"`
+	StartTime     time.Time   `json:"start_time"`
+}
+
+type BookingFailed struct {
+	Header        EventHeader `json:"header"`
+	BookingID     string      `json:"booking_id"`
+	FailureReason string      `json:"failure_reason"`
+}
+
+type BookingCanceled struct {
+	Header    EventHeader `json:"header"`
+	BookingID string      `json:"booking_id"`
+	ShowID    string      `json:"show_id"`
+}
+
+type FlightBooked struct {
+	Header    EventHeader `json:"header"`
+	BookingID string      `json:"booking_id"`
+	FlightID  string      `json:"flight_id"`
+	TicketIDs []string    `json:"ticket_ids"`
+}
+
+type FlightBookingFailed struct {
+	Header        EventHeader `json:"header"`
+	BookingID     string      `json:"booking_id"`
+	FlightID      string      `json:"flight_id"`
+	FailureReason string      `json:"failure_reason"`
+}
+
+type FlightTicketsCanceled struct {
+	Header    EventHeader `json:"header"`
+	BookingID string      `json:"booking_id"`
+	FlightID  string      `json:"flight_id"`
+}
+
+type TaxiBooked struct {
+	Header        EventHeader `json:"header"`
+	BookingID     string      `json:"booking_id"`
+	TaxiBookingID string      `json:"taxi_booking_id"`
+}
+
+type TaxiBookingFailed struct {
+	Header        EventHeader `json:"header"`
+	BookingID     string      `json:"booking_id"`
+	FailureReason string      `json:"failure_reason"`
+}
+
+type VipBundleInitialized struct {
+	Header      EventHeader `json:"header"`
+	VipBundleID string      `json:"vip_bundle_id"`
+}
+
+type VipBundleFinalized struct {
+	Header      EventHeader `json:"header"`
+	VipBundleID string      `json:"vip_bundle_id"`
+	Success     bool        `json:"success"`
+}
@@ -0,0 +1,15 @@
+package entities
+
+import "errors"
+
+// ErrFlightBookingRejected means the transportation API refused the booking (for example no seats left), retrying won't help.
+var ErrFlightBookingRejected = errors.New("flight booking rejected")
+
+type FlightBookingRequest struct {
+	// ReferenceID is our booking ID.
+	ReferenceID    string
+	FlightID       string
+	CustomerEmail  string
+	PassengerNames []string
+	IdempotencyKey string
+}
@@ -1,6 +1,83 @@
 package entities
 
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+var ErrCurrencyMismatch = errors.New("amounts are in different currencies")
+
 type Money struct {
 	Amount   string `json:"amount"`
 	Currency string `json:"currency"`
 }
+
+// Percent returns percent of the amount, rounded down to cents (or to the amount's precision, if finer).
+// The result always has at least two decimals, also when the whole amount is returned.
+func (m Money) Percent(percent int) (Money, error) {
+	amount, err := m.rat()
+	if err != nil {
+		return Money{}, err
+	}
+
+	decimals := max(2, m.decimals())
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+
+	// amount * percent / 100, in units of 10^-decimals
+	units := new(big.Rat).Mul(amount, big.NewRat(int64(percent), 100))
+	units.Mul(units, new(big.Rat).SetInt(scale))
+	truncated := new(big.Int).Quo(units.Num(), units.Denom())
+
+	return Money{
+		Amount:   new(big.Rat).SetFrac(truncated, scale).FloatString(decimals),
+		Currency: m.Currency,
+	}, nil
+}
+
+// Minus subtracts other from the amount, never going below zero.
+func (m Money) Minus(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+
+	amount, err := m.rat()
+	if err != nil {
+		return Money{}, err
+	}
+
+	subtracted, err := other.rat()
+	if err != nil {
+		return Money{}, err
+	}
+
+	result := new(big.Rat).Sub(amount, subtracted)
+	if result.Sign() < 0 {
+		result.SetInt64(0)
+	}
+
+	return Money{
+		Amount:   result.FloatString(max(m.decimals(), other.decimals())),
+		Currency: m.Currency,
+	}, nil
+}
+
+func (m Money) Positive() bool {
+	amount, err := m.rat()
+	return err == nil && amount.Sign() > 0
+}
+
+func (m Money) rat() (*big.Rat, error) {
+	amount, ok := new(big.Rat).SetString(m.Amount)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount %q", m.Amount)
+	}
+
+	return amount, nil
+}
+
+func (m Money) decimals() int {
+	_, fraction, _ := strings.Cut(m.Amount, ".")
+	return len(fraction)
+}
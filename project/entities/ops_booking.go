@@ -0,0 +1,58 @@
+package entities
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+var ErrOpsBookingNotFound = errors.New("ops booking not found")
+
+// OpsBooking is a read model of a booking and the lifecycle of its tickets, built for support.
+type OpsBooking struct {
+	BookingID       string               `json:"booking_id"`
+	BookedAt        *time.Time           `json:"booked_at,omitempty"`
+	ShowID          string               `json:"show_id,omitempty"`
+	NumberOfTickets int                  `json:"number_of_tickets,omitempty"`
+	CustomerEmail   string               `json:"customer_email,omitempty"`
+	VIP             bool                 `json:"vip"`
+	Tickets         map[string]OpsTicket `json:"tickets"`
+}
+
+type OpsTicket struct {
+	Price           Money      `json:"price"`
+	CustomerEmail   string     `json:"customer_email"`
+	ConfirmedAt     *time.Time `json:"confirmed_at,omitempty"`
+	CanceledAt      *time.Time `json:"canceled_at,omitempty"`
+	RefundedAt      *time.Time `json:"refunded_at,omitempty"`
+	PrintedAt       *time.Time `json:"printed_at,omitempty"`
+	PrintedFileName string     `json:"printed_file_name,omitempty"`
+	ReceiptIssuedAt *time.Time `json:"receipt_issued_at,omitempty"`
+	ReceiptNumber   string     `json:"receipt_number,omitempty"`
+}
+
+// ReceiptIssueDates returns the distinct dates (UTC) receipts for the booking's tickets were issued on.
+func (b OpsBooking) ReceiptIssueDates() []string {
+	var dates []string
+	for _, ticket := range b.Tickets {
+		if ticket.ReceiptIssuedAt == nil {
+			continue
+		}
+
+		date := ticket.ReceiptIssuedAt.UTC().Format(time.DateOnly)
+		if !slices.Contains(dates, date) {
+			dates = append(dates, date)
+		}
+	}
+
+	return dates
+}
+
+// OpsBookingsFilter selects a page of ops bookings, ordered by their IDs.
+type OpsBookingsFilter struct {
+	// ReceiptIssueDate keeps only bookings with a receipt issued on the date (UTC) when it's set.
+	ReceiptIssueDate *time.Time
+	// AfterBookingID is the ID of the last booking of the previous page.
+	AfterBookingID string
+	Limit          int
+}
@@ -0,0 +1,49 @@
+package entities
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrPromoCodeNotFound             = errors.New("promo code not found")
+	ErrPromoCodeExists               = errors.New("promo code already exists")
+	ErrPromoCodeNotActive            = errors.New("promo code is not valid at this time")
+	ErrPromoCodeUsedUp               = errors.New("promo code was used the maximum number of times")
+	ErrPromoCodeCustomerLimitReached = errors.New("promo code was used by the customer the maximum number of times")
+)
+
+// PromoCode discounts the tickets of bookings made with it, either by PercentOff or by AmountOff per ticket.
+// Each booking counts as one use, bookings released before confirmation give their use back.
+type PromoCode struct {
+	Code       string `json:"code" db:"code"`
+	PercentOff int    `json:"percent_off,omitempty" db:"percent_off"`
+	AmountOff  *Money `json:"amount_off,omitempty" db:"-"`
+	// ValidFrom and ValidUntil limit when bookings can use the code, both are optional.
+	ValidFrom          *time.Time `json:"valid_from,omitempty" db:"valid_from"`
+	ValidUntil         *time.Time `json:"valid_until,omitempty" db:"valid_until"`
+	MaxUses            *int       `json:"max_uses,omitempty" db:"max_uses"`
+	MaxUsesPerCustomer *int       `json:"max_uses_per_customer,omitempty" db:"max_uses_per_customer"`
+	Uses               int        `json:"uses" db:"uses"`
+	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
+}
+
+func (p PromoCode) ActiveAt(t time.Time) error {
+	if p.ValidFrom != nil && t.Before(*p.ValidFrom) {
+		return ErrPromoCodeNotActive
+	}
+	if p.ValidUntil != nil && !t.Before(*p.ValidUntil) {
+		return ErrPromoCodeNotActive
+	}
+
+	return nil
+}
+
+// Apply returns the price of a ticket after the discount.
+func (p PromoCode) Apply(price Money) (Money, error) {
+	if p.AmountOff != nil {
+		return price.Minus(*p.AmountOff)
+	}
+
+	return price.Percent(100 - p.PercentOff)
+}
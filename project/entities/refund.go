@@ -0,0 +1,37 @@
+package entities
+
+import (
+	"slices"
+	"time"
+)
+
+// RefundPolicy lists the share of the price refunded depending on how long before the show a ticket is canceled.
+// Cancellations not covered by any rule are not refunded, tickets of shows without a policy are refunded in full.
+type RefundPolicy []RefundRule
+
+type RefundRule struct {
+	// HoursBeforeShow is the minimum time between the cancellation and the start of the show.
+	HoursBeforeShow int `json:"hours_before_show"`
+	Percent         int `json:"percent"`
+}
+
+// Percent returns the refunded share of the price for a ticket canceled at canceledAt,
+// the rule with the longest matching time before the show wins.
+func (p RefundPolicy) Percent(showStart time.Time, canceledAt time.Time) int {
+	if len(p) == 0 {
+		return 100
+	}
+
+	rules := slices.Clone(p)
+	slices.SortFunc(rules, func(a, b RefundRule) int {
+		return b.HoursBeforeShow - a.HoursBeforeShow
+	})
+
+	for _, rule := range rules {
+		if !canceledAt.After(showStart.Add(-time.Duration(rule.HoursBeforeShow) * time.Hour)) {
+			return rule.Percent
+		}
+	}
+
+	return 0
+}
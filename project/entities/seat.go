@@ -0,0 +1,65 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrSeatsRequired   = errors.New("the show has a seat map, a seat must be chosen for each ticket")
+	ErrSeatsNotAllowed = errors.New("the show has no seat map, seats can't be chosen")
+	ErrSeatNotFound    = errors.New("seat not found in the show's seat map")
+	ErrSeatTaken       = errors.New("seat is already booked")
+)
+
+// SeatMap lays out the seats of a show, seats of a row are numbered from 1.
+type SeatMap struct {
+	Sections []SeatSection `json:"sections"`
+}
+
+type SeatSection struct {
+	Name string    `json:"name"`
+	Rows []SeatRow `json:"rows"`
+}
+
+type SeatRow struct {
+	Name  string `json:"name"`
+	Seats int    `json:"seats"`
+}
+
+func (m SeatMap) Capacity() int {
+	capacity := 0
+	for _, section := range m.Sections {
+		for _, row := range section.Rows {
+			capacity += row.Seats
+		}
+	}
+
+	return capacity
+}
+
+func (m SeatMap) Contains(seat Seat) bool {
+	for _, section := range m.Sections {
+		if section.Name != seat.Section {
+			continue
+		}
+
+		for _, row := range section.Rows {
+			if row.Name == seat.Row {
+				return seat.Number >= 1 && seat.Number <= row.Seats
+			}
+		}
+	}
+
+	return false
+}
+
+type Seat struct {
+	Section string `json:"section" db:"section"`
+	Row     string `json:"row" db:"seat_row"`
+	Number  int    `json:"number" db:"seat_number"`
+}
+
+func (s Seat) String() string {
+	return fmt.Sprintf("%s/%s/%d", s.Section, s.Row, s.Number)
+}
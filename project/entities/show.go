@@ -0,0 +1,20 @@
+package entities
+
+import "time"
+
+type Show struct {
+	ShowID           string    `json:"show_id" db:"show_id"`
+	Title            string    `json:"title" db:"title"`
+	Venue            string    `json:"venue" db:"venue"`
+	StartTime        time.Time `json:"start_time" db:"start_time"`
+	NumberOfTickets  int       `json:"number_of_tickets" db:"number_of_tickets"`
+	ExternalProvider bool      `json:"external_provider" db:"external_provider"`
+	// DeadNationID is the show's event ID in Dead Nation, set for shows sold through the partner.
+	DeadNationID *string `json:"dead_nation_id,omitempty" db:"dead_nation_id"`
+	// SeatMap is set for shows with assigned seating, their bookings choose the seats.
+	SeatMap *SeatMap `json:"seat_map,omitempty" db:"-"`
+	// RefundPolicy decides how much of the price is refunded for canceled tickets, without it the full price is refunded.
+	RefundPolicy RefundPolicy `json:"refund_policy,omitempty" db:"-"`
+	// TicketPrice is the price of a ticket, promo codes can be used only for shows that have it.
+	TicketPrice *Money `json:"ticket_price,omitempty" db:"-"`
+}
@@ -0,0 +1,12 @@
+package entities
+
+import "time"
+
+// ShowReminder tracks the reminder of a ticket's show, so it's sent once and not after the ticket is canceled.
+type ShowReminder struct {
+	TicketID string     `db:"ticket_id"`
+	Canceled bool       `db:"canceled"`
+	SentAt   *time.Time `db:"sent_at"`
+	// ClaimedUntil is set while the reminder is being sent.
+	ClaimedUntil *time.Time `db:"claimed_until"`
+}
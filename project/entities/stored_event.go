@@ -0,0 +1,17 @@
+package entities
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+type StoredEvent struct {
+	EventID       string          `json:"event_id" db:"event_id"`
+	EventName     string          `json:"event_name" db:"event_name"`
+	PublishedAt   time.Time       `json:"published_at" db:"published_at"`
+	CorrelationID string          `json:"correlation_id" db:"correlation_id"`
+	Payload       json.RawMessage `json:"payload" db:"payload"`
+}
+
+var ErrProjectionNotFound = errors.New("projection not found")
@@ -0,0 +1,15 @@
+package entities
+
+import "errors"
+
+// ErrTaxiBookingRejected means the transportation API refused the booking, retrying won't help.
+var ErrTaxiBookingRejected = errors.New("taxi booking rejected")
+
+type TaxiBookingRequest struct {
+	// ReferenceID is our booking ID.
+	ReferenceID        string
+	CustomerEmail      string
+	PassengerName      string
+	NumberOfPassengers int
+	IdempotencyKey     string
+}
@@ -2,9 +2,12 @@ package entities
 
 type Ticket struct {
 	TicketID      string `json:"ticket_id"`
+	BookingID     string `json:"booking_id,omitempty"`
 	Status        string `json:"status"`
 	CustomerEmail string `json:"customer_email"`
-	Price         Money  `json:"price"`
+	// Locale is the customer's preferred language of notifications, for example "en".
+	Locale string `json:"locale,omitempty"`
+	Price  Money  `json:"price"`
 }
 
 type TicketsStatusRequest struct {
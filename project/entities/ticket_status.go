@@ -0,0 +1,21 @@
+package entities
+
+import "time"
+
+const (
+	TicketStatusConfirmed = "confirmed"
+	TicketStatusCanceled  = "canceled"
+	TicketStatusReceipted = "receipted"
+	TicketStatusRefunded  = "refunded"
+)
+
+// TicketStatusChanged is pushed to clients of the tickets stream.
+type TicketStatusChanged struct {
+	EventID       string    `json:"event_id"`
+	TicketID      string    `json:"ticket_id"`
+	BookingID     string    `json:"booking_id,omitempty"`
+	ShowID        string    `json:"show_id,omitempty"`
+	CustomerEmail string    `json:"customer_email,omitempty"`
+	Status        string    `json:"status"`
+	ChangedAt     time.Time `json:"changed_at"`
+}
@@ -0,0 +1,46 @@
+package entities
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrVipBundleNotFound = errors.New("vip bundle not found")
+
+type VipBundleStatus string
+
+const (
+	VipBundleStatusInProgress VipBundleStatus = "in_progress"
+	// VipBundleStatusCompensating means a step failed and the completed steps are being rolled back.
+	VipBundleStatusCompensating VipBundleStatus = "compensating"
+	VipBundleStatusFinalized    VipBundleStatus = "finalized"
+	VipBundleStatusFailed       VipBundleStatus = "failed"
+)
+
+// VipBundle is the state of the VIP bundle saga, which books show tickets, an inbound flight,
+// a return flight and a taxi, in that order.
+type VipBundle struct {
+	VipBundleID     string   `json:"vip_bundle_id"`
+	BookingID       string   `json:"booking_id"`
+	CustomerEmail   string   `json:"customer_email"`
+	NumberOfTickets int      `json:"number_of_tickets"`
+	ShowID          string   `json:"show_id"`
+	Passengers      []string `json:"passengers"`
+	InboundFlightID string   `json:"inbound_flight_id"`
+	ReturnFlightID  string   `json:"return_flight_id"`
+
+	Status        VipBundleStatus `json:"status"`
+	FailureReason string          `json:"failure_reason,omitempty"`
+
+	BookingMade            bool     `json:"booking_made"`
+	InboundFlightTicketIDs []string `json:"inbound_flight_ticket_ids,omitempty"`
+	ReturnFlightTicketIDs  []string `json:"return_flight_ticket_ids,omitempty"`
+	TaxiBookingID          string   `json:"taxi_booking_id,omitempty"`
+
+	BookingCanceled       bool `json:"booking_canceled"`
+	InboundFlightCanceled bool `json:"inbound_flight_canceled"`
+	ReturnFlightCanceled  bool `json:"return_flight_canceled"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
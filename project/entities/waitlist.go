@@ -0,0 +1,27 @@
+package entities
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrWaitlistEntryNotFound = errors.New("waitlist entry not found")
+	ErrWaitlistOfferNotMade  = errors.New("no tickets were offered to the waitlist entry yet")
+	ErrWaitlistOfferExpired  = errors.New("waitlist offer expired")
+	ErrShowNotSoldOut        = errors.New("the show has enough tickets left to book them")
+)
+
+// WaitlistEntry is a customer waiting for tickets of a show. Freed tickets are offered by booking them
+// for the customer, with the entry's ID as the booking's ID, until the booking expires.
+// The customer claims the offer before that, and then pays for the tickets like for other bookings.
+type WaitlistEntry struct {
+	EntryID         string     `json:"entry_id" db:"entry_id"`
+	ShowID          string     `json:"show_id" db:"show_id"`
+	CustomerEmail   string     `json:"customer_email" db:"customer_email"`
+	NumberOfTickets int        `json:"number_of_tickets" db:"number_of_tickets"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	OfferedAt       *time.Time `json:"offered_at,omitempty" db:"offered_at"`
+	// ClaimedAt is when the customer accepted the offer.
+	ClaimedAt *time.Time `json:"claimed_at,omitempty" db:"claimed_at"`
+}
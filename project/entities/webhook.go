@@ -0,0 +1,68 @@
+package entities
+
+import (
+	"errors"
+	"net"
+	"slices"
+	"time"
+)
+
+var ErrWebhookNotFound = errors.New("webhook not found")
+
+// IsPublicWebhookAddress reports whether a webhook endpoint may be at ip,
+// so webhooks can't be used to reach the service's own network.
+func IsPublicWebhookAddress(ip net.IP) bool {
+	return !ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast()
+}
+
+// WebhookEventTypes are the events partners can subscribe to.
+var WebhookEventTypes = []string{
+	"TicketBookingConfirmed",
+	"TicketBookingCanceled",
+	"TicketReceiptIssued",
+	"TicketPrinted",
+}
+
+type Webhook struct {
+	WebhookID  string   `json:"webhook_id" db:"webhook_id"`
+	URL        string   `json:"url" db:"url"`
+	EventTypes []string `json:"event_types" db:"-"`
+	// ShowIDs are the partner's shows, only events of tickets booked for them are delivered.
+	ShowIDs []string `json:"show_ids" db:"-"`
+	// Secret signs the deliveries, it's never returned by the API.
+	Secret              string    `json:"-" db:"secret"`
+	Disabled            bool      `json:"disabled" db:"disabled"`
+	ConsecutiveFailures int       `json:"consecutive_failures" db:"consecutive_failures"`
+	CreatedAt           time.Time `json:"created_at" db:"created_at"`
+}
+
+func (w Webhook) SubscribedTo(eventName string) bool {
+	return slices.Contains(w.EventTypes, eventName)
+}
+
+func (w Webhook) CoversShow(showID string) bool {
+	return slices.Contains(w.ShowIDs, showID)
+}
+
+type WebhookAttempt struct {
+	AttemptID   string    `json:"attempt_id" db:"attempt_id"`
+	WebhookID   string    `json:"webhook_id" db:"webhook_id"`
+	EventID     string    `json:"event_id" db:"event_id"`
+	EventName   string    `json:"event_name" db:"event_name"`
+	StatusCode  int       `json:"status_code,omitempty" db:"status_code"`
+	Error       string    `json:"error,omitempty" db:"error"`
+	Succeeded   bool      `json:"succeeded" db:"succeeded"`
+	AttemptedAt time.Time `json:"attempted_at" db:"attempted_at"`
+}
+
+type WebhookRequest struct {
+	URL     string
+	Headers map[string]string
+	Body    []byte
+}
@@ -1,9 +1,87 @@
 package http
 
 import (
+	"context"
+	"tickets/entities"
+	"time"
+
 	"github.com/ThreeDotsLabs/watermill/components/cqrs"
 )
 
 type Handler struct {
-	eventBus *cqrs.EventBus
+	eventBus              *cqrs.EventBus
+	outbox                Outbox
+	showsRepository       ShowsRepository
+	bookingsRepository    BookingsRepository
+	vipBundlesRepository  VipBundlesRepository
+	eventsRepository      EventsRepository
+	eventReplayer         EventReplayer
+	opsBookingsRepository OpsBookingsRepository
+	ticketsStream         TicketsStream
+	webhooksRepository    WebhooksRepository
+	waitlistRepository    WaitlistRepository
+	promoCodesRepository  PromoCodesRepository
+	shuttingDown          <-chan struct{}
+	readinessChecks       map[string]ReadinessCheck
+	now                   func() time.Time
+	reservationTTL        time.Duration
+	// allowPrivateWebhookAddresses allows registering webhooks on private hosts, for local development
+	allowPrivateWebhookAddresses bool
+}
+
+// Outbox marshals events for repositories to store with the changes they're about.
+type Outbox interface {
+	OutboxMessage(ctx context.Context, event any) (entities.DelayedMessage, error)
+}
+
+type ShowsRepository interface {
+	AddShow(ctx context.Context, show entities.Show, outbox ...entities.DelayedMessage) error
+	AllShows(ctx context.Context) ([]entities.Show, error)
+	ShowByID(ctx context.Context, showID string) (entities.Show, error)
+}
+
+type BookingsRepository interface {
+	AddBooking(ctx context.Context, booking entities.Booking, outbox ...entities.DelayedMessage) error
+	BookingByID(ctx context.Context, bookingID string) (entities.Booking, error)
+	AssignSeat(ctx context.Context, bookingID string, ticketID string) (*entities.Seat, error)
+	ConfirmBooking(ctx context.Context, bookingID string, now time.Time) error
+}
+
+type VipBundlesRepository interface {
+	Add(ctx context.Context, vipBundle entities.VipBundle, outbox ...entities.DelayedMessage) error
+	ByID(ctx context.Context, vipBundleID string) (entities.VipBundle, error)
+}
+
+type EventsRepository interface {
+	EventsByTicketID(ctx context.Context, ticketID string) ([]entities.StoredEvent, error)
+}
+
+type EventReplayer interface {
+	Replay(ctx context.Context, projectionName string) (int, error)
+}
+
+type OpsBookingsRepository interface {
+	All(ctx context.Context, filter entities.OpsBookingsFilter) ([]entities.OpsBooking, error)
+	ByID(ctx context.Context, bookingID string) (entities.OpsBooking, error)
+}
+
+type TicketsStream interface {
+	Subscribe(ctx context.Context, lastEventID string) (<-chan entities.TicketStatusChanged, error)
+}
+
+type WebhooksRepository interface {
+	Add(ctx context.Context, webhook entities.Webhook) error
+	ByID(ctx context.Context, webhookID string) (entities.Webhook, error)
+	AttemptsByWebhookID(ctx context.Context, webhookID string) ([]entities.WebhookAttempt, error)
+}
+
+type WaitlistRepository interface {
+	Add(ctx context.Context, entry entities.WaitlistEntry) error
+	ByID(ctx context.Context, entryID string) (entities.WaitlistEntry, error)
+	Claim(ctx context.Context, entryID string, now time.Time, outbox ...entities.DelayedMessage) error
+}
+
+type PromoCodesRepository interface {
+	Add(ctx context.Context, promoCode entities.PromoCode) error
+	ByCode(ctx context.Context, code string) (entities.PromoCode, error)
 }
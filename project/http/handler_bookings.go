@@ -0,0 +1,132 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"tickets/entities"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type PostBookTicketsRequest struct {
+	ShowID          string `json:"show_id"`
+	NumberOfTickets int    `json:"number_of_tickets"`
+	CustomerEmail   string `json:"customer_email"`
+	// VIP bookings include a taxi to the venue.
+	VIP bool `json:"vip"`
+	// Seats must be chosen for shows with a seat map, number_of_tickets defaults to their number.
+	Seats []entities.Seat `json:"seats"`
+	// PromoCode is optional, it discounts the tickets of shows with a ticket price.
+	PromoCode string `json:"promo_code"`
+}
+
+type PostBookTicketsResponse struct {
+	BookingID string `json:"booking_id"`
+}
+
+func (h Handler) PostBookTickets(c echo.Context) error {
+	var request PostBookTicketsRequest
+	err := c.Bind(&request)
+	if err != nil {
+		return err
+	}
+
+	if request.ShowID == "" || request.CustomerEmail == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "show_id and customer_email are required")
+	}
+	if uuid.Validate(request.ShowID) != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "show_id must be a UUID")
+	}
+	if request.NumberOfTickets == 0 {
+		request.NumberOfTickets = len(request.Seats)
+	}
+	if request.NumberOfTickets <= 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "number_of_tickets must be positive")
+	}
+
+	expiresAt := h.now().Add(h.reservationTTL).UTC()
+
+	booking := entities.Booking{
+		BookingID:       uuid.NewString(),
+		ShowID:          request.ShowID,
+		NumberOfTickets: request.NumberOfTickets,
+		CustomerEmail:   request.CustomerEmail,
+		VIP:             request.VIP,
+		ExpiresAt:       &expiresAt,
+		Seats:           request.Seats,
+	}
+
+	if request.PromoCode != "" {
+		promoCode, err := h.promoCodesRepository.ByCode(c.Request().Context(), request.PromoCode)
+		if errors.Is(err, entities.ErrPromoCodeNotFound) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		if err != nil {
+			return err
+		}
+
+		err = promoCode.ActiveAt(h.now())
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+
+		show, err := h.showsRepository.ShowByID(c.Request().Context(), booking.ShowID)
+		if errors.Is(err, entities.ErrShowNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		if err != nil {
+			return err
+		}
+		if show.TicketPrice == nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "promo codes can't be used for shows without a ticket price")
+		}
+
+		price, err := promoCode.Apply(*show.TicketPrice)
+		if errors.Is(err, entities.ErrCurrencyMismatch) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		if err != nil {
+			return fmt.Errorf("failed to apply promo code %s: %w", promoCode.Code, err)
+		}
+
+		booking.PromoCode = &promoCode.Code
+		booking.OriginalPrice = show.TicketPrice
+		booking.Price = &price
+	}
+
+	outboxMessage, err := h.outbox.OutboxMessage(c.Request().Context(), entities.BookingMade{
+		Header:          entities.NewEventHeader(),
+		BookingID:       booking.BookingID,
+		ShowID:          booking.ShowID,
+		NumberOfTickets: booking.NumberOfTickets,
+		CustomerEmail:   booking.CustomerEmail,
+		VIP:             booking.VIP,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = h.bookingsRepository.AddBooking(c.Request().Context(), booking, outboxMessage)
+	if errors.Is(err, entities.ErrShowNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	if errors.Is(err, entities.ErrNotEnoughTickets) ||
+		errors.Is(err, entities.ErrSeatsRequired) ||
+		errors.Is(err, entities.ErrSeatsNotAllowed) ||
+		errors.Is(err, entities.ErrSeatNotFound) ||
+		errors.Is(err, entities.ErrPromoCodeNotFound) ||
+		errors.Is(err, entities.ErrPromoCodeUsedUp) ||
+		errors.Is(err, entities.ErrPromoCodeCustomerLimitReached) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if errors.Is(err, entities.ErrSeatTaken) {
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, PostBookTicketsResponse{BookingID: booking.BookingID})
+}
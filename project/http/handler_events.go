@@ -0,0 +1,45 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"tickets/entities"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (h Handler) GetOpsEvents(c echo.Context) error {
+	ticketID := c.QueryParam("ticket_id")
+	if ticketID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "ticket_id is required")
+	}
+
+	events, err := h.eventsRepository.EventsByTicketID(c.Request().Context(), ticketID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, events)
+}
+
+type PostOpsProjectionReplayResponse struct {
+	Projection     string `json:"projection"`
+	ReplayedEvents int    `json:"replayed_events"`
+}
+
+func (h Handler) PostOpsProjectionReplay(c echo.Context) error {
+	projectionName := c.Param("name")
+
+	replayed, err := h.eventReplayer.Replay(c.Request().Context(), projectionName)
+	if errors.Is(err, entities.ErrProjectionNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, PostOpsProjectionReplayResponse{
+		Projection:     projectionName,
+		ReplayedEvents: replayed,
+	})
+}
@@ -0,0 +1,55 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const readinessCheckTimeout = 2 * time.Second
+
+// ReadinessCheck returns an error when the dependency it checks is not usable.
+type ReadinessCheck func(ctx context.Context) error
+
+type healthResponse struct {
+	Status string                           `json:"status"`
+	Checks map[string]dependencyCheckResult `json:"checks,omitempty"`
+}
+
+type dependencyCheckResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func (h Handler) GetHealthLive(c echo.Context) error {
+	return c.JSON(http.StatusOK, healthResponse{Status: "ok"})
+}
+
+func (h Handler) GetHealthReady(c echo.Context) error {
+	response := healthResponse{
+		Status: "ok",
+		Checks: make(map[string]dependencyCheckResult, len(h.readinessChecks)),
+	}
+
+	for name, check := range h.readinessChecks {
+		ctx, cancel := context.WithTimeout(c.Request().Context(), readinessCheckTimeout)
+		err := check(ctx)
+		cancel()
+
+		if err != nil {
+			response.Status = "unavailable"
+			response.Checks[name] = dependencyCheckResult{Status: "unavailable", Error: err.Error()}
+			continue
+		}
+
+		response.Checks[name] = dependencyCheckResult{Status: "ok"}
+	}
+
+	if response.Status != "ok" {
+		return c.JSON(http.StatusServiceUnavailable, response)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
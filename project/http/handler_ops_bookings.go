@@ -0,0 +1,69 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"tickets/entities"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultOpsBookingsLimit = 100
+	maxOpsBookingsLimit     = 1000
+)
+
+// GetOpsBookings returns a page of bookings ordered by their IDs,
+// the next page starts after the ID of the last booking of the page.
+func (h Handler) GetOpsBookings(c echo.Context) error {
+	filter := entities.OpsBookingsFilter{Limit: defaultOpsBookingsLimit}
+
+	if param := c.QueryParam("receipt_issue_date"); param != "" {
+		date, err := time.Parse(time.DateOnly, param)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "receipt_issue_date must be in YYYY-MM-DD format")
+		}
+
+		filter.ReceiptIssueDate = &date
+	}
+
+	if param := c.QueryParam("after"); param != "" {
+		if _, err := uuid.Parse(param); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "after must be a booking ID")
+		}
+
+		filter.AfterBookingID = param
+	}
+
+	if param := c.QueryParam("limit"); param != "" {
+		limit, err := strconv.Atoi(param)
+		if err != nil || limit < 1 || limit > maxOpsBookingsLimit {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxOpsBookingsLimit))
+		}
+
+		filter.Limit = limit
+	}
+
+	opsBookings, err := h.opsBookingsRepository.All(c.Request().Context(), filter)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, opsBookings)
+}
+
+func (h Handler) GetOpsBooking(c echo.Context) error {
+	opsBooking, err := h.opsBookingsRepository.ByID(c.Request().Context(), c.Param("id"))
+	if errors.Is(err, entities.ErrOpsBookingNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, opsBooking)
+}
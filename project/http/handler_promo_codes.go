@@ -0,0 +1,91 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"tickets/entities"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type PostPromoCodesRequest struct {
+	Code string `json:"code"`
+	// Either PercentOff or AmountOff (per ticket) is required.
+	PercentOff         int             `json:"percent_off"`
+	AmountOff          *entities.Money `json:"amount_off"`
+	ValidFrom          *time.Time      `json:"valid_from"`
+	ValidUntil         *time.Time      `json:"valid_until"`
+	MaxUses            *int            `json:"max_uses"`
+	MaxUsesPerCustomer *int            `json:"max_uses_per_customer"`
+}
+
+type PostPromoCodesResponse struct {
+	Code string `json:"code"`
+}
+
+func (h Handler) PostPromoCodes(c echo.Context) error {
+	var request PostPromoCodesRequest
+	err := c.Bind(&request)
+	if err != nil {
+		return err
+	}
+
+	if request.Code == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "code is required")
+	}
+	if (request.PercentOff == 0) == (request.AmountOff == nil) {
+		return echo.NewHTTPError(http.StatusBadRequest, "either percent_off or amount_off is required")
+	}
+	if request.PercentOff < 0 || request.PercentOff > 100 {
+		return echo.NewHTTPError(http.StatusBadRequest, "percent_off must be between 1 and 100")
+	}
+	if request.AmountOff != nil {
+		if request.AmountOff.Currency == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, "amount_off currency is required")
+		}
+		if !request.AmountOff.Positive() {
+			return echo.NewHTTPError(http.StatusBadRequest, "amount_off amount must be a positive number")
+		}
+	}
+	if request.ValidFrom != nil && request.ValidUntil != nil && !request.ValidUntil.After(*request.ValidFrom) {
+		return echo.NewHTTPError(http.StatusBadRequest, "valid_until must be after valid_from")
+	}
+	if (request.MaxUses != nil && *request.MaxUses <= 0) ||
+		(request.MaxUsesPerCustomer != nil && *request.MaxUsesPerCustomer <= 0) {
+		return echo.NewHTTPError(http.StatusBadRequest, "max_uses and max_uses_per_customer must be positive")
+	}
+
+	promoCode := entities.PromoCode{
+		Code:               request.Code,
+		PercentOff:         request.PercentOff,
+		AmountOff:          request.AmountOff,
+		ValidFrom:          request.ValidFrom,
+		ValidUntil:         request.ValidUntil,
+		MaxUses:            request.MaxUses,
+		MaxUsesPerCustomer: request.MaxUsesPerCustomer,
+		CreatedAt:          h.now().UTC(),
+	}
+
+	err = h.promoCodesRepository.Add(c.Request().Context(), promoCode)
+	if errors.Is(err, entities.ErrPromoCodeExists) {
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, PostPromoCodesResponse{Code: promoCode.Code})
+}
+
+func (h Handler) GetPromoCode(c echo.Context) error {
+	promoCode, err := h.promoCodesRepository.ByCode(c.Request().Context(), c.Param("code"))
+	if errors.Is(err, entities.ErrPromoCodeNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, promoCode)
+}
@@ -0,0 +1,176 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"tickets/entities"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type PostShowRequest struct {
+	Title            string    `json:"title"`
+	Venue            string    `json:"venue"`
+	StartTime        time.Time `json:"start_time"`
+	NumberOfTickets  int       `json:"number_of_tickets"`
+	ExternalProvider bool      `json:"external_provider"`
+	DeadNationID     string    `json:"dead_nation_id"`
+	// SeatMap is optional, number_of_tickets defaults to its capacity.
+	SeatMap *entities.SeatMap `json:"seat_map"`
+	// RefundPolicy is optional, canceled tickets are refunded in full without it.
+	RefundPolicy entities.RefundPolicy `json:"refund_policy"`
+	// TicketPrice is optional, promo codes can be used only for shows that have it.
+	TicketPrice *entities.Money `json:"ticket_price"`
+}
+
+type PostShowResponse struct {
+	ShowID string `json:"show_id"`
+}
+
+func (h Handler) PostShows(c echo.Context) error {
+	var request PostShowRequest
+	err := c.Bind(&request)
+	if err != nil {
+		return err
+	}
+
+	if request.Title == "" || request.Venue == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "title and venue are required")
+	}
+	if request.StartTime.IsZero() {
+		return echo.NewHTTPError(http.StatusBadRequest, "start_time is required")
+	}
+	if request.SeatMap != nil {
+		err = validateSeatMap(*request.SeatMap)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+
+		capacity := request.SeatMap.Capacity()
+		if request.NumberOfTickets == 0 {
+			request.NumberOfTickets = capacity
+		}
+		if request.NumberOfTickets != capacity {
+			return echo.NewHTTPError(http.StatusBadRequest, "number_of_tickets must match the capacity of the seat map")
+		}
+	}
+	if request.NumberOfTickets <= 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "number_of_tickets must be positive")
+	}
+	if err := validateRefundPolicy(request.RefundPolicy); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if request.TicketPrice != nil {
+		if request.TicketPrice.Currency == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, "ticket_price currency is required")
+		}
+		if !request.TicketPrice.Positive() {
+			return echo.NewHTTPError(http.StatusBadRequest, "ticket_price amount must be a positive number")
+		}
+	}
+	if request.ExternalProvider && request.DeadNationID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "dead_nation_id is required for shows sold by an external provider")
+	}
+	if request.DeadNationID != "" && uuid.Validate(request.DeadNationID) != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "dead_nation_id must be a UUID")
+	}
+
+	show := entities.Show{
+		ShowID:           uuid.NewString(),
+		Title:            request.Title,
+		Venue:            request.Venue,
+		StartTime:        request.StartTime,
+		NumberOfTickets:  request.NumberOfTickets,
+		ExternalProvider: request.ExternalProvider,
+		SeatMap:          request.SeatMap,
+		RefundPolicy:     request.RefundPolicy,
+		TicketPrice:      request.TicketPrice,
+	}
+	if request.DeadNationID != "" {
+		show.DeadNationID = &request.DeadNationID
+	}
+
+	outboxMessage, err := h.outbox.OutboxMessage(c.Request().Context(), entities.ShowCreated{
+		Header:           entities.NewEventHeader(),
+		ShowID:           show.ShowID,
+		Title:            show.Title,
+		Venue:            show.Venue,
+		StartTime:        show.StartTime,
+		NumberOfTickets:  show.NumberOfTickets,
+		ExternalProvider: show.ExternalProvider,
+		DeadNationID:     show.DeadNationID,
+		SeatMap:          show.SeatMap,
+		RefundPolicy:     show.RefundPolicy,
+		TicketPrice:      show.TicketPrice,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = h.showsRepository.AddShow(c.Request().Context(), show, outboxMessage)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, PostShowResponse{ShowID: show.ShowID})
+}
+
+func validateSeatMap(seatMap entities.SeatMap) error {
+	if len(seatMap.Sections) == 0 {
+		return errors.New("seat_map needs at least one section")
+	}
+
+	sections := map[string]bool{}
+	for _, section := range seatMap.Sections {
+		if section.Name == "" || sections[section.Name] {
+			return errors.New("seat_map sections need unique names")
+		}
+		sections[section.Name] = true
+
+		if len(section.Rows) == 0 {
+			return fmt.Errorf("seat_map section %s needs at least one row", section.Name)
+		}
+
+		rows := map[string]bool{}
+		for _, row := range section.Rows {
+			if row.Name == "" || rows[row.Name] {
+				return fmt.Errorf("seat_map rows of section %s need unique names", section.Name)
+			}
+			rows[row.Name] = true
+
+			if row.Seats <= 0 {
+				return fmt.Errorf("seat_map row %s of section %s needs a positive number of seats", row.Name, section.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateRefundPolicy(policy entities.RefundPolicy) error {
+	hours := map[int]bool{}
+	for _, rule := range policy {
+		if rule.HoursBeforeShow < 0 || hours[rule.HoursBeforeShow] {
+			return errors.New("refund_policy rules need unique, non-negative hours_before_show")
+		}
+		hours[rule.HoursBeforeShow] = true
+
+		if rule.Percent < 0 || rule.Percent > 100 {
+			return errors.New("refund_policy percent must be between 0 and 100")
+		}
+	}
+
+	return nil
+}
+
+func (h Handler) GetShows(c echo.Context) error {
+	shows, err := h.showsRepository.AllShows(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, shows)
+}
@@ -1,6 +1,8 @@
 package http
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"tickets/entities"
@@ -15,35 +17,102 @@ func (h Handler) PostTicketsStatus(c echo.Context) error {
 		return err
 	}
 
+	// confirmed tickets keep their bookings from expiring, tickets of expired bookings are rejected before any is processed
 	for _, ticket := range request.Tickets {
-		if ticket.Status == "confirmed" {
+		if ticket.Status != "confirmed" && ticket.Status != "canceled" {
+			return fmt.Errorf("unknown ticket status: %s", ticket.Status)
+		}
+		if ticket.Status != "confirmed" || ticket.BookingID == "" {
+			continue
+		}
+
+		err = h.bookingsRepository.ConfirmBooking(c.Request().Context(), ticket.BookingID, h.now().UTC())
+		if errors.Is(err, entities.ErrBookingExpired) {
+			return echo.NewHTTPError(
+				http.StatusConflict,
+				fmt.Sprintf("booking %s of ticket %s expired", ticket.BookingID, ticket.TicketID),
+			)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, ticket := range request.Tickets {
+		switch ticket.Status {
+		case "confirmed":
+			price, originalPrice, promoCode, err := h.ticketPrice(c.Request().Context(), ticket)
+			if err != nil {
+				return err
+			}
+
+			var seat *entities.Seat
+			if ticket.BookingID != "" {
+				seat, err = h.bookingsRepository.AssignSeat(c.Request().Context(), ticket.BookingID, ticket.TicketID)
+				if err != nil {
+					return err
+				}
+			}
+
 			event := entities.TicketBookingConfirmed{
 				Header:        entities.NewEventHeader(),
 				TicketID:      ticket.TicketID,
+				BookingID:     ticket.BookingID,
 				CustomerEmail: ticket.CustomerEmail,
-				Price:         ticket.Price,
+				Locale:        ticket.Locale,
+				Price:         price,
+				OriginalPrice: originalPrice,
+				PromoCode:     promoCode,
+				Seat:          seat,
 			}
 
 			err = h.eventBus.Publish(c.Request().Context(), event)
 			if err != nil {
 				return fmt.Errorf("failed to publish TicketBookingConfirmed event: %w", err)
 			}
-		} else if ticket.Status == "canceled" {
+		case "canceled":
+			price, _, _, err := h.ticketPrice(c.Request().Context(), ticket)
+			if err != nil {
+				return err
+			}
+
 			event := entities.TicketBookingCanceled{
 				Header:        entities.NewEventHeader(),
 				TicketID:      ticket.TicketID,
+				BookingID:     ticket.BookingID,
 				CustomerEmail: ticket.CustomerEmail,
-				Price:         ticket.Price,
+				Locale:        ticket.Locale,
+				// refunds are based on what the customer paid
+				Price: price,
 			}
 
 			err = h.eventBus.Publish(c.Request().Context(), event)
 			if err != nil {
 				return fmt.Errorf("failed to publish TicketBookingCanceled event: %w", err)
 			}
-		} else {
-			return fmt.Errorf("unknown ticket status: %s", ticket.Status)
 		}
 	}
 
 	return c.NoContent(http.StatusOK)
 }
+
+// ticketPrice returns the discounted and the original price of a ticket, as stored on its booking when it was made
+// with a promo code, and the code itself.
+func (h Handler) ticketPrice(ctx context.Context, ticket entities.Ticket) (entities.Money, entities.Money, string, error) {
+	if ticket.BookingID == "" {
+		return ticket.Price, ticket.Price, "", nil
+	}
+
+	booking, err := h.bookingsRepository.BookingByID(ctx, ticket.BookingID)
+	if errors.Is(err, entities.ErrBookingNotFound) {
+		return ticket.Price, ticket.Price, "", nil
+	}
+	if err != nil {
+		return entities.Money{}, entities.Money{}, "", err
+	}
+	if booking.PromoCode == nil || booking.Price == nil || booking.OriginalPrice == nil {
+		return ticket.Price, ticket.Price, "", nil
+	}
+
+	return *booking.Price, *booking.OriginalPrice, *booking.PromoCode, nil
+}
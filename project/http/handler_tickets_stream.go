@@ -0,0 +1,73 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ticketsStreamHeartbeat keeps idle connections from being closed by proxies.
+const ticketsStreamHeartbeat = 15 * time.Second
+
+// GetTicketsStream pushes ticket status changes as Server-Sent Events, optionally only of a show or a customer.
+// Clients reconnecting with Last-Event-ID first get the changes they missed.
+func (h Handler) GetTicketsStream(c echo.Context) error {
+	showID := c.QueryParam("show_id")
+	customerEmail := c.QueryParam("customer_email")
+
+	ctx, cancel := context.WithCancel(c.Request().Context())
+	defer cancel()
+
+	changes, err := h.ticketsStream.Subscribe(ctx, c.Request().Header.Get("Last-Event-ID"))
+	if err != nil {
+		return err
+	}
+
+	resp := c.Response()
+	resp.Header().Set(echo.HeaderContentType, "text/event-stream")
+	resp.Header().Set(echo.HeaderCacheControl, "no-cache")
+	resp.Header().Set(echo.HeaderConnection, "keep-alive")
+	resp.WriteHeader(http.StatusOK)
+	resp.Flush()
+
+	heartbeat := time.NewTicker(ticketsStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case change, ok := <-changes:
+			if !ok {
+				return nil
+			}
+			if showID != "" && change.ShowID != showID {
+				continue
+			}
+			if customerEmail != "" && change.CustomerEmail != customerEmail {
+				continue
+			}
+
+			data, err := json.Marshal(change)
+			if err != nil {
+				return fmt.Errorf("failed to marshal ticket status change: %w", err)
+			}
+
+			_, err = fmt.Fprintf(resp, "id: %s\nevent: %s\ndata: %s\n\n", change.EventID, change.Status, data)
+			if err != nil {
+				return nil
+			}
+			resp.Flush()
+		case <-heartbeat.C:
+			_, err := fmt.Fprint(resp, ": heartbeat\n\n")
+			if err != nil {
+				return nil
+			}
+			resp.Flush()
+		case <-h.shuttingDown:
+			return nil
+		}
+	}
+}
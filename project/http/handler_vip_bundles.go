@@ -0,0 +1,95 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"tickets/entities"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type PostBookVipBundleRequest struct {
+	ShowID          string   `json:"show_id"`
+	NumberOfTickets int      `json:"number_of_tickets"`
+	CustomerEmail   string   `json:"customer_email"`
+	Passengers      []string `json:"passengers"`
+	InboundFlightID string   `json:"inbound_flight_id"`
+	ReturnFlightID  string   `json:"return_flight_id"`
+}
+
+type PostBookVipBundleResponse struct {
+	VipBundleID string `json:"vip_bundle_id"`
+	BookingID   string `json:"booking_id"`
+}
+
+func (h Handler) PostBookVipBundle(c echo.Context) error {
+	var request PostBookVipBundleRequest
+	err := c.Bind(&request)
+	if err != nil {
+		return err
+	}
+
+	if request.ShowID == "" || request.CustomerEmail == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "show_id and customer_email are required")
+	}
+	if request.NumberOfTickets <= 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "number_of_tickets must be positive")
+	}
+	if len(request.Passengers) == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "passengers are required")
+	}
+	if uuid.Validate(request.InboundFlightID) != nil || uuid.Validate(request.ReturnFlightID) != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "inbound_flight_id and return_flight_id must be UUIDs")
+	}
+	if request.InboundFlightID == request.ReturnFlightID {
+		return echo.NewHTTPError(http.StatusBadRequest, "inbound and return flights must be different")
+	}
+
+	now := time.Now().UTC()
+
+	vipBundle := entities.VipBundle{
+		VipBundleID:     uuid.NewString(),
+		BookingID:       uuid.NewString(),
+		CustomerEmail:   request.CustomerEmail,
+		NumberOfTickets: request.NumberOfTickets,
+		ShowID:          request.ShowID,
+		Passengers:      request.Passengers,
+		InboundFlightID: request.InboundFlightID,
+		ReturnFlightID:  request.ReturnFlightID,
+		Status:          entities.VipBundleStatusInProgress,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	outboxMessage, err := h.outbox.OutboxMessage(c.Request().Context(), entities.VipBundleInitialized{
+		Header:      entities.NewEventHeader(),
+		VipBundleID: vipBundle.VipBundleID,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = h.vipBundlesRepository.Add(c.Request().Context(), vipBundle, outboxMessage)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, PostBookVipBundleResponse{
+		VipBundleID: vipBundle.VipBundleID,
+		BookingID:   vipBundle.BookingID,
+	})
+}
+
+func (h Handler) GetOpsVipBundle(c echo.Context) error {
+	vipBundle, err := h.vipBundlesRepository.ByID(c.Request().Context(), c.Param("id"))
+	if errors.Is(err, entities.ErrVipBundleNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, vipBundle)
+}
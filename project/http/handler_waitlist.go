@@ -0,0 +1,119 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"tickets/entities"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type PostWaitlistRequest struct {
+	CustomerEmail   string `json:"customer_email"`
+	NumberOfTickets int    `json:"number_of_tickets"`
+}
+
+type PostWaitlistResponse struct {
+	EntryID string `json:"entry_id"`
+}
+
+type PostWaitlistClaimResponse struct {
+	BookingID string `json:"booking_id"`
+}
+
+func (h Handler) PostWaitlist(c echo.Context) error {
+	var request PostWaitlistRequest
+	err := c.Bind(&request)
+	if err != nil {
+		return err
+	}
+
+	if request.CustomerEmail == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "customer_email is required")
+	}
+	if request.NumberOfTickets == 0 {
+		request.NumberOfTickets = 1
+	}
+	if request.NumberOfTickets < 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "number_of_tickets must be positive")
+	}
+
+	if uuid.Validate(c.Param("id")) != nil {
+		return echo.NewHTTPError(http.StatusNotFound, entities.ErrShowNotFound.Error())
+	}
+
+	show, err := h.showsRepository.ShowByID(c.Request().Context(), c.Param("id"))
+	if errors.Is(err, entities.ErrShowNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	if err != nil {
+		return err
+	}
+
+	// offered tickets are booked for the customer, who can't choose seats in advance
+	if show.SeatMap != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "shows with a seat map have no waitlist")
+	}
+
+	entry := entities.WaitlistEntry{
+		EntryID:         uuid.NewString(),
+		ShowID:          show.ShowID,
+		CustomerEmail:   request.CustomerEmail,
+		NumberOfTickets: request.NumberOfTickets,
+		CreatedAt:       h.now().UTC(),
+	}
+
+	err = h.waitlistRepository.Add(c.Request().Context(), entry)
+	if errors.Is(err, entities.ErrShowNotSoldOut) {
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, PostWaitlistResponse{EntryID: entry.EntryID})
+}
+
+// PostWaitlistClaim accepts the tickets offered to the waitlist entry, which are then paid for like other bookings.
+func (h Handler) PostWaitlistClaim(c echo.Context) error {
+	if uuid.Validate(c.Param("id")) != nil {
+		return echo.NewHTTPError(http.StatusNotFound, entities.ErrWaitlistEntryNotFound.Error())
+	}
+
+	entry, err := h.waitlistRepository.ByID(c.Request().Context(), c.Param("id"))
+	if errors.Is(err, entities.ErrWaitlistEntryNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	if err != nil {
+		return err
+	}
+
+	// offered tickets are booked with the entry's ID
+	outboxMessage, err := h.outbox.OutboxMessage(c.Request().Context(), entities.BookingMade{
+		Header:          entities.NewEventHeader(),
+		BookingID:       entry.EntryID,
+		ShowID:          entry.ShowID,
+		NumberOfTickets: entry.NumberOfTickets,
+		CustomerEmail:   entry.CustomerEmail,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = h.waitlistRepository.Claim(c.Request().Context(), entry.EntryID, h.now().UTC(), outboxMessage)
+	if errors.Is(err, entities.ErrWaitlistEntryNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	if errors.Is(err, entities.ErrWaitlistOfferNotMade) {
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
+	if errors.Is(err, entities.ErrWaitlistOfferExpired) {
+		return echo.NewHTTPError(http.StatusGone, err.Error())
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, PostWaitlistClaimResponse{BookingID: entry.EntryID})
+}
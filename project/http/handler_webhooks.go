@@ -0,0 +1,119 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"tickets/entities"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type PostWebhooksRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	ShowIDs    []string `json:"show_ids"`
+	Secret     string   `json:"secret"`
+}
+
+type PostWebhooksResponse struct {
+	WebhookID string `json:"webhook_id"`
+}
+
+type GetWebhookResponse struct {
+	entities.Webhook
+	Attempts []entities.WebhookAttempt `json:"attempts"`
+}
+
+func (h Handler) PostWebhooks(c echo.Context) error {
+	var request PostWebhooksRequest
+	err := c.Bind(&request)
+	if err != nil {
+		return err
+	}
+
+	endpoint, err := url.Parse(request.URL)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Hostname() == "" || endpoint.User != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "url must be an absolute http(s) URL without credentials")
+	}
+	if !h.allowPrivateWebhookAddresses && !isPublicHost(endpoint.Hostname()) {
+		return echo.NewHTTPError(http.StatusBadRequest, "url must point to a public host")
+	}
+	if request.Secret == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "secret is required")
+	}
+	if len(request.EventTypes) == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "event_types are required")
+	}
+	for _, eventType := range request.EventTypes {
+		if !slices.Contains(entities.WebhookEventTypes, eventType) {
+			return echo.NewHTTPError(
+				http.StatusBadRequest,
+				fmt.Sprintf("unknown event type %s, supported: %v", eventType, entities.WebhookEventTypes),
+			)
+		}
+	}
+
+	if len(request.ShowIDs) == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "show_ids are required")
+	}
+	for _, showID := range request.ShowIDs {
+		if uuid.Validate(showID) != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid show id %s", showID))
+		}
+	}
+
+	webhook := entities.Webhook{
+		WebhookID:  uuid.NewString(),
+		URL:        request.URL,
+		EventTypes: request.EventTypes,
+		ShowIDs:    request.ShowIDs,
+		Secret:     request.Secret,
+		CreatedAt:  time.Now().UTC(),
+	}
+
+	err = h.webhooksRepository.Add(c.Request().Context(), webhook)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, PostWebhooksResponse{WebhookID: webhook.WebhookID})
+}
+
+// isPublicHost rejects hosts known to be private without resolving them,
+// the addresses hosts resolve to are checked again whenever a webhook is delivered.
+func isPublicHost(host string) bool {
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+
+	ip := net.ParseIP(host)
+
+	return ip == nil || entities.IsPublicWebhookAddress(ip)
+}
+
+func (h Handler) GetWebhook(c echo.Context) error {
+	webhook, err := h.webhooksRepository.ByID(c.Request().Context(), c.Param("id"))
+	if errors.Is(err, entities.ErrWebhookNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	if err != nil {
+		return err
+	}
+
+	attempts, err := h.webhooksRepository.AttemptsByWebhookID(c.Request().Context(), webhook.WebhookID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, GetWebhookResponse{
+		Webhook:  webhook,
+		Attempts: attempts,
+	})
+}
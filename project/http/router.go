@@ -1,25 +1,104 @@
 package http
 
 import (
+	"crypto/subtle"
 	"github.com/ThreeDotsLabs/watermill/components/cqrs"
 	"net/http"
+	"tickets/config"
+	"time"
 
 	commonHTTP "github.com/ThreeDotsLabs/go-event-driven/common/http"
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
 )
 
-func NewHttpRouter(eventBus *cqrs.EventBus) *echo.Echo {
+func NewHttpRouter(
+	eventBus *cqrs.EventBus,
+	outbox Outbox,
+	showsRepository ShowsRepository,
+	bookingsRepository BookingsRepository,
+	vipBundlesRepository VipBundlesRepository,
+	eventsRepository EventsRepository,
+	eventReplayer EventReplayer,
+	opsBookingsRepository OpsBookingsRepository,
+	ticketsStream TicketsStream,
+	webhooksRepository WebhooksRepository,
+	waitlistRepository WaitlistRepository,
+	promoCodesRepository PromoCodesRepository,
+	readinessChecks map[string]ReadinessCheck,
+	now func() time.Time,
+	reservationTTL time.Duration,
+	webhooksConfig config.Webhooks,
+) *echo.Echo {
 	e := commonHTTP.NewEcho()
 
 	e.GET("/health", func(c echo.Context) error {
 		return c.String(http.StatusOK, "ok")
 	})
 
+	// streams are never done on their own, so they end when the server starts shutting down
+	shuttingDown := make(chan struct{})
+	e.Server.RegisterOnShutdown(func() {
+		close(shuttingDown)
+	})
+
 	handler := Handler{
-		eventBus: eventBus,
+		eventBus:              eventBus,
+		outbox:                outbox,
+		showsRepository:       showsRepository,
+		bookingsRepository:    bookingsRepository,
+		vipBundlesRepository:  vipBundlesRepository,
+		eventsRepository:      eventsRepository,
+		eventReplayer:         eventReplayer,
+		opsBookingsRepository: opsBookingsRepository,
+		ticketsStream:         ticketsStream,
+		webhooksRepository:    webhooksRepository,
+		waitlistRepository:    waitlistRepository,
+		promoCodesRepository:  promoCodesRepository,
+		shuttingDown:          shuttingDown,
+		readinessChecks:       readinessChecks,
+		now:                   now,
+		reservationTTL:        reservationTTL,
+
+		allowPrivateWebhookAddresses: webhooksConfig.AllowPrivateAddresses,
 	}
 
+	e.GET("/health/live", handler.GetHealthLive)
+	e.GET("/health/ready", handler.GetHealthReady)
+
 	e.POST("/tickets-status", handler.PostTicketsStatus)
+	e.GET("/tickets/stream", handler.GetTicketsStream)
+
+	e.POST("/shows", handler.PostShows)
+	e.GET("/shows", handler.GetShows)
+	e.POST("/shows/:id/waitlist", handler.PostWaitlist)
+	e.POST("/waitlist/:id/claim", handler.PostWaitlistClaim)
+
+	e.POST("/book-tickets", handler.PostBookTickets)
+	e.POST("/book-vip-bundle", handler.PostBookVipBundle)
+
+	// admin endpoints expose customers' data, so they're only served with the admin token
+	if webhooksConfig.AdminToken != "" {
+		adminAuth := middleware.KeyAuth(func(key string, c echo.Context) (bool, error) {
+			return subtle.ConstantTimeCompare([]byte(key), []byte(webhooksConfig.AdminToken)) == 1, nil
+		})
+
+		// webhooks are registered by partners' integrations set up by us, as deliveries are sent from our network
+		webhooks := e.Group("/webhooks", adminAuth)
+		webhooks.POST("", handler.PostWebhooks)
+		webhooks.GET("/:id", handler.GetWebhook)
+
+		promoCodes := e.Group("/promo-codes", adminAuth)
+		promoCodes.POST("", handler.PostPromoCodes)
+		promoCodes.GET("/:code", handler.GetPromoCode)
+
+		ops := e.Group("/ops", adminAuth)
+		ops.GET("/bookings", handler.GetOpsBookings)
+		ops.GET("/bookings/:id", handler.GetOpsBooking)
+		ops.GET("/events", handler.GetOpsEvents)
+		ops.GET("/vip-bundles/:id", handler.GetOpsVipBundle)
+		ops.POST("/projections/:name/replay", handler.PostOpsProjectionReplay)
+	}
 
 	return e
 }
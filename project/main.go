@@ -6,8 +6,12 @@ import (
 	"os"
 	"os/signal"
 	"tickets/api"
+	"tickets/config"
+	"tickets/db"
 	"tickets/message"
+	"tickets/message/event"
 	"tickets/service"
+	"time"
 
 	"github.com/ThreeDotsLabs/go-event-driven/common/clients"
 	"github.com/ThreeDotsLabs/go-event-driven/common/log"
@@ -17,8 +21,13 @@ func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
 
+	cfg, err := config.Load()
+	if err != nil {
+		panic(err)
+	}
+
 	apiClients, err := clients.NewClients(
-		os.Getenv("GATEWAY_ADDR"),
+		cfg.Gateway.Addr,
 		func(ctx context.Context, req *http.Request) error {
 			req.Header.Set("Correlation-ID", log.CorrelationIDFromContext(ctx))
 			return nil
@@ -28,16 +37,56 @@ func main() {
 		panic(err)
 	}
 
-	redisClient := message.NewRedisClient(os.Getenv("REDIS_ADDR"))
-	defer redisClient.Close()
+	dbConn, err := db.NewPostgresConnection(cfg.Postgres.URL)
+	if err != nil {
+		panic(err)
+	}
+	defer dbConn.Close()
+
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		if len(os.Args) != 3 {
+			panic("usage: tickets replay <projection>")
+		}
+
+		replayed, err := service.Replay(ctx, dbConn, os.Args[2])
+		if err != nil {
+			panic(err)
+		}
+
+		log.FromContext(ctx).Infof("Replayed %d events into %s", replayed, os.Args[2])
+		return
+	}
+
+	// closed by the service at the end of the shutdown sequence
+	redisClient := message.NewRedisClient(cfg.Redis)
 
 	spreadsheetsService := api.NewSpreadsheetsServiceClient(apiClients)
 	receiptsService := api.NewReceiptsServiceClient(apiClients)
+	deadNationService := api.NewDeadNationClient(apiClients, cfg.Gateway.Addr)
+	filesService := api.NewFilesServiceClient(apiClients)
+	var notificationsService event.NotificationsService
+	if cfg.SMTP.Enabled() {
+		notificationsService = api.NewSMTPNotificationsClient(cfg.SMTP)
+	}
+	transportationService := api.NewTransportationClient(apiClients)
+	paymentsService := api.NewPaymentsServiceClient(apiClients)
+	webhookClient := api.NewWebhookClient(cfg.Webhooks)
+	gatewayHealthChecker := api.NewGatewayHealthChecker(cfg.Gateway.Addr)
 
 	err = service.New(
+		cfg,
+		dbConn,
 		redisClient,
 		spreadsheetsService,
 		receiptsService,
+		deadNationService,
+		filesService,
+		notificationsService,
+		transportationService,
+		paymentsService,
+		webhookClient,
+		gatewayHealthChecker.Check,
+		time.Now,
 	).Run(ctx)
 	if err != nil {
 		panic(err)
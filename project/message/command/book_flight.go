@@ -0,0 +1,41 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"tickets/entities"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+)
+
+func (h Handler) BookFlight(ctx context.Context, command *entities.BookFlight) error {
+	log.FromContext(ctx).Info("Booking flight")
+
+	ticketIDs, err := h.transportationService.BookFlight(ctx, entities.FlightBookingRequest{
+		ReferenceID:    command.BookingID,
+		FlightID:       command.FlightID,
+		CustomerEmail:  command.CustomerEmail,
+		PassengerNames: command.PassengerNames,
+		// the same tickets are returned when the command is redelivered
+		IdempotencyKey: "book-flight-" + command.BookingID + "-" + command.FlightID,
+	})
+	if errors.Is(err, entities.ErrFlightBookingRejected) {
+		return h.publish(ctx, entities.FlightBookingFailed{
+			Header:        entities.NewEventHeader(),
+			BookingID:     command.BookingID,
+			FlightID:      command.FlightID,
+			FailureReason: err.Error(),
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to book flight: %w", err)
+	}
+
+	return h.publish(ctx, entities.FlightBooked{
+		Header:    entities.NewEventHeader(),
+		BookingID: command.BookingID,
+		FlightID:  command.FlightID,
+		TicketIDs: ticketIDs,
+	})
+}
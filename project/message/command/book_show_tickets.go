@@ -0,0 +1,44 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"tickets/entities"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+)
+
+func (h Handler) BookShowTickets(ctx context.Context, command *entities.BookShowTickets) error {
+	log.FromContext(ctx).Info("Booking show tickets")
+
+	outboxMessage, err := h.outbox.OutboxMessage(ctx, entities.BookingMade{
+		Header:          entities.NewEventHeader(),
+		BookingID:       command.BookingID,
+		ShowID:          command.ShowID,
+		NumberOfTickets: command.NumberOfTickets,
+		CustomerEmail:   command.CustomerEmail,
+	})
+	if err != nil {
+		return err
+	}
+
+	// the bundle is compensated when its tickets aren't confirmed in time, like other reservations
+	expiresAt := h.now().Add(h.reservationTTL).UTC()
+
+	err = h.bookingsRepository.AddBooking(ctx, entities.Booking{
+		BookingID:       command.BookingID,
+		ShowID:          command.ShowID,
+		NumberOfTickets: command.NumberOfTickets,
+		CustomerEmail:   command.CustomerEmail,
+		ExpiresAt:       &expiresAt,
+	}, outboxMessage)
+	if errors.Is(err, entities.ErrNotEnoughTickets) || errors.Is(err, entities.ErrShowNotFound) {
+		return h.publish(ctx, entities.BookingFailed{
+			Header:        entities.NewEventHeader(),
+			BookingID:     command.BookingID,
+			FailureReason: err.Error(),
+		})
+	}
+
+	return err
+}
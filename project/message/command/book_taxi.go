@@ -0,0 +1,66 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"tickets/entities"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+)
+
+func (h Handler) BookTaxi(ctx context.Context, command *entities.BookTaxi) error {
+	log.FromContext(ctx).Info("Booking taxi")
+
+	taxiBookingID, err := h.transportationService.BookTaxi(ctx, entities.TaxiBookingRequest{
+		ReferenceID:   command.BookingID,
+		CustomerEmail: command.CustomerEmail,
+		// we don't ask for passenger names when booking tickets
+		PassengerName:      command.CustomerEmail,
+		NumberOfPassengers: command.NumberOfPassengers,
+		// the same taxi is returned when the command is redelivered
+		IdempotencyKey: "book-taxi-" + command.BookingID,
+	})
+	if errors.Is(err, entities.ErrTaxiBookingRejected) {
+		failureReason := err.Error()
+
+		// there will be no taxi for CancelTaxiBooking to wait for
+		err = h.bookingsRepository.MarkTaxiCancelled(ctx, command.BookingID)
+		if err != nil {
+			return err
+		}
+
+		return h.publish(ctx, entities.TaxiBookingFailed{
+			Header:        entities.NewEventHeader(),
+			BookingID:     command.BookingID,
+			FailureReason: failureReason,
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to book taxi: %w", err)
+	}
+
+	err = h.bookingsRepository.SetTaxiBookingID(ctx, command.BookingID, taxiBookingID)
+	if errors.Is(err, entities.ErrTaxiCancelled) || errors.Is(err, entities.ErrBookingNotFound) {
+		// the booking was canceled while the taxi was being booked, CancelTaxiBooking had no taxi to cancel
+		err = h.transportationService.CancelTaxiBooking(ctx, taxiBookingID)
+		if err != nil {
+			return fmt.Errorf("failed to cancel taxi booking %s: %w", taxiBookingID, err)
+		}
+
+		return h.publish(ctx, entities.TaxiBookingFailed{
+			Header:        entities.NewEventHeader(),
+			BookingID:     command.BookingID,
+			FailureReason: "booking was canceled",
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	return h.publish(ctx, entities.TaxiBooked{
+		Header:        entities.NewEventHeader(),
+		BookingID:     command.BookingID,
+		TaxiBookingID: taxiBookingID,
+	})
+}
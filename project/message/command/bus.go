@@ -0,0 +1,87 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"tickets/entities"
+	"time"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+type DelayedMessagesRepository interface {
+	Add(ctx context.Context, msg entities.DelayedMessage) error
+}
+
+// Bus sends commands right away, or later with SendAfter. Delayed commands are stored
+// until the delayed messages relay publishes them to the command's topic.
+type Bus struct {
+	*cqrs.CommandBus
+
+	delayedMessages DelayedMessagesRepository
+	marshaler       cqrs.CommandEventMarshaler
+}
+
+func NewCommandBus(pub message.Publisher, delayedMessages DelayedMessagesRepository) *Bus {
+	if delayedMessages == nil {
+		panic("missing delayedMessages")
+	}
+
+	marshaler := cqrs.JSONMarshaler{GenerateName: cqrs.StructName}
+
+	bus, err := cqrs.NewCommandBusWithConfig(pub, cqrs.CommandBusConfig{
+		GeneratePublishTopic: func(params cqrs.CommandBusGeneratePublishTopicParams) (string, error) {
+			return topic(params.CommandName), nil
+		},
+		Marshaler: marshaler,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return &Bus{
+		CommandBus:      bus,
+		delayedMessages: delayedMessages,
+		marshaler:       marshaler,
+	}
+}
+
+func (b *Bus) SendAfter(ctx context.Context, command any, delay time.Duration) error {
+	msg, err := b.delayedMessage(ctx, command, time.Now().Add(delay))
+	if err != nil {
+		return err
+	}
+
+	return b.delayedMessages.Add(ctx, msg)
+}
+
+// OutboxMessage marshals the command for a repository to store in the transaction of the change it follows from,
+// it's sent by the delayed messages relay once the transaction is committed.
+func (b *Bus) OutboxMessage(ctx context.Context, command any) (entities.DelayedMessage, error) {
+	return b.delayedMessage(ctx, command, time.Now())
+}
+
+func (b *Bus) delayedMessage(ctx context.Context, command any, publishAt time.Time) (entities.DelayedMessage, error) {
+	msg, err := b.marshaler.Marshal(command)
+	if err != nil {
+		return entities.DelayedMessage{}, fmt.Errorf("failed to marshal %s command: %w", cqrs.StructName(command), err)
+	}
+
+	// the relay publishes without the caller's context
+	msg.Metadata.Set("correlation_id", log.CorrelationIDFromContext(ctx))
+
+	return entities.DelayedMessage{
+		MessageID: msg.UUID,
+		Topic:     topic(b.marshaler.Name(command)),
+		Payload:   msg.Payload,
+		Metadata:  msg.Metadata,
+		PublishAt: publishAt.UTC(),
+	}, nil
+}
+
+// topic keeps commands apart from events, which are published to topics named after them.
+func topic(commandName string) string {
+	return "commands." + commandName
+}
@@ -0,0 +1,23 @@
+package command
+
+import (
+	"context"
+	"tickets/entities"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+)
+
+func (h Handler) CancelBooking(ctx context.Context, command *entities.CancelBooking) error {
+	log.FromContext(ctx).Info("Cancelling booking")
+
+	err := h.bookingsRepository.RemoveBooking(ctx, command.BookingID)
+	if err != nil {
+		return err
+	}
+
+	return h.publish(ctx, entities.BookingCanceled{
+		Header:    entities.NewEventHeader(),
+		BookingID: command.BookingID,
+		ShowID:    command.ShowID,
+	})
+}
@@ -0,0 +1,25 @@
+package command
+
+import (
+	"context"
+	"tickets/entities"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+)
+
+func (h Handler) CancelFlightTickets(ctx context.Context, command *entities.CancelFlightTickets) error {
+	log.FromContext(ctx).Info("Cancelling flight tickets")
+
+	for _, flightTicketID := range command.FlightTicketIDs {
+		err := h.transportationService.CancelFlightTicket(ctx, flightTicketID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return h.publish(ctx, entities.FlightTicketsCanceled{
+		Header:    entities.NewEventHeader(),
+		BookingID: command.BookingID,
+		FlightID:  command.FlightID,
+	})
+}
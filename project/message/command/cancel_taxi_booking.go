@@ -0,0 +1,61 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"tickets/entities"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+)
+
+func (h Handler) CancelTaxiBooking(ctx context.Context, command *entities.CancelTaxiBooking) error {
+	log.FromContext(ctx).Info("Cancelling taxi booking")
+
+	booking, err := h.bookingsRepository.BookingByID(ctx, command.BookingID)
+	if errors.Is(err, entities.ErrBookingNotFound) {
+		// BookTaxi cancels the taxis it books for removed bookings itself, stored ones are passed with the command
+		if command.TaxiBookingID == "" {
+			return nil
+		}
+
+		err = h.transportationService.CancelTaxiBooking(ctx, command.TaxiBookingID)
+		if err != nil {
+			return fmt.Errorf("failed to cancel taxi booking %s: %w", command.TaxiBookingID, err)
+		}
+
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get booking %s: %w", command.BookingID, err)
+	}
+
+	if booking.TaxiCancelled {
+		// another ticket of the booking was canceled before
+		return nil
+	}
+
+	if booking.TaxiBookingID == nil {
+		// there is nothing to cancel, marking the taxi cancelled keeps BookTaxi in progress from keeping its taxi
+		err = h.bookingsRepository.MarkTaxiCancelled(ctx, command.BookingID)
+		if err != nil {
+			return err
+		}
+
+		// BookTaxi may have stored its taxi before the mark
+		booking, err = h.bookingsRepository.BookingByID(ctx, command.BookingID)
+		if err != nil {
+			return fmt.Errorf("failed to get booking %s: %w", command.BookingID, err)
+		}
+		if booking.TaxiBookingID == nil {
+			return nil
+		}
+	}
+
+	err = h.transportationService.CancelTaxiBooking(ctx, *booking.TaxiBookingID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel taxi booking %s: %w", *booking.TaxiBookingID, err)
+	}
+
+	return h.bookingsRepository.MarkTaxiCancelled(ctx, command.BookingID)
+}
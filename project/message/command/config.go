@@ -0,0 +1,29 @@
+package command
+
+import (
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill-redisstream/pkg/redisstream"
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/redis/go-redis/v9"
+)
+
+func NewProcessorConfig(
+	redisClient *redis.Client,
+	consumerGroupPrefix string,
+	logger watermill.LoggerAdapter,
+) cqrs.CommandProcessorConfig {
+	return cqrs.CommandProcessorConfig{
+		GenerateSubscribeTopic: func(params cqrs.CommandProcessorGenerateSubscribeTopicParams) (string, error) {
+			return topic(params.CommandName), nil
+		},
+		SubscriberConstructor: func(params cqrs.CommandProcessorSubscriberConstructorParams) (message.Subscriber, error) {
+			return redisstream.NewSubscriber(redisstream.SubscriberConfig{
+				Client:        redisClient,
+				ConsumerGroup: consumerGroupPrefix + params.HandlerName,
+			}, logger)
+		},
+		Marshaler: cqrs.JSONMarshaler{GenerateName: cqrs.StructName},
+		Logger:    logger,
+	}
+}
@@ -0,0 +1,110 @@
+package command
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"tickets/entities"
+	"time"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+	"github.com/google/uuid"
+)
+
+// DeliverWebhook sends the event to the endpoint once per call. A failed attempt sends the command again
+// with a delay doubling each time, until the attempts run out, so an endpoint that's down doesn't hold up
+// deliveries to the others for longer than the attempt took.
+func (h Handler) DeliverWebhook(ctx context.Context, command *entities.DeliverWebhook) error {
+	logger := log.FromContext(ctx).WithField("webhook_id", command.WebhookID)
+
+	webhook, err := h.webhooksRepository.ByID(ctx, command.WebhookID)
+	if errors.Is(err, entities.ErrWebhookNotFound) {
+		logger.Warn("Webhook not found, skipping delivery")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if webhook.Disabled {
+		logger.Info("Webhook is disabled, skipping delivery")
+		return nil
+	}
+
+	// attempts are counted in the database, so redelivered commands don't start over
+	attempts, err := h.webhooksRepository.Attempts(ctx, webhook.WebhookID, command.EventID)
+	if err != nil {
+		return err
+	}
+	for _, attempt := range attempts {
+		if attempt.Succeeded {
+			return nil
+		}
+	}
+	if len(attempts) >= h.webhooksConfig.MaxAttempts {
+		return nil
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	statusCode, deliveryErr := h.webhookSender.SendWebhook(ctx, entities.WebhookRequest{
+		URL: webhook.URL,
+		Headers: map[string]string{
+			"Content-Type":        "application/json",
+			"X-Webhook-Event":     command.EventName,
+			"X-Webhook-Event-ID":  command.EventID,
+			"X-Webhook-Timestamp": timestamp,
+			"X-Webhook-Signature": signWebhook(webhook.Secret, timestamp, command.Payload),
+		},
+		Body: command.Payload,
+	})
+
+	attempt := entities.WebhookAttempt{
+		AttemptID:   uuid.NewString(),
+		WebhookID:   webhook.WebhookID,
+		EventID:     command.EventID,
+		EventName:   command.EventName,
+		StatusCode:  statusCode,
+		Succeeded:   deliveryErr == nil,
+		AttemptedAt: time.Now().UTC(),
+	}
+	if deliveryErr != nil {
+		attempt.Error = deliveryErr.Error()
+	}
+
+	gaveUp := deliveryErr != nil && len(attempts)+1 >= h.webhooksConfig.MaxAttempts
+
+	err = h.webhooksRepository.RecordAttempt(ctx, attempt, gaveUp, h.webhooksConfig.DisableAfterFailures)
+	if err != nil {
+		return err
+	}
+
+	if gaveUp {
+		logger.WithError(deliveryErr).Warnf("Giving up delivering event %s", command.EventID)
+		return nil
+	}
+	if deliveryErr != nil {
+		delay := h.webhooksConfig.RetryInterval << len(attempts)
+		logger.WithError(deliveryErr).Infof("Failed to deliver event %s, retrying in %s", command.EventID, delay)
+
+		err = h.commandBus.SendAfter(ctx, command, delay)
+		if err != nil {
+			return fmt.Errorf("failed to schedule delivery of event %s: %w", command.EventID, err)
+		}
+	}
+
+	return nil
+}
+
+// signWebhook signs the timestamp along with the body, so partners can reject replayed deliveries.
+func signWebhook(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,132 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"tickets/config"
+	"tickets/entities"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+)
+
+type Handler struct {
+	eventBus              *cqrs.EventBus
+	outbox                Outbox
+	commandBus            *Bus
+	transportationService TransportationService
+	paymentsService       PaymentsService
+	bookingsRepository    BookingsRepository
+	webhooksRepository    WebhooksRepository
+	webhookSender         WebhookSender
+	webhooksConfig        config.Webhooks
+	reservationTTL        time.Duration
+	now                   func() time.Time
+}
+
+func NewHandler(
+	eventBus *cqrs.EventBus,
+	outbox Outbox,
+	commandBus *Bus,
+	transportationService TransportationService,
+	paymentsService PaymentsService,
+	bookingsRepository BookingsRepository,
+	webhooksRepository WebhooksRepository,
+	webhookSender WebhookSender,
+	webhooksConfig config.Webhooks,
+	reservations config.Reservations,
+	now func() time.Time,
+) Handler {
+	if eventBus == nil {
+		panic("missing eventBus")
+	}
+
+	if outbox == nil {
+		panic("missing outbox")
+	}
+
+	if commandBus == nil {
+		panic("missing commandBus")
+	}
+
+	if transportationService == nil {
+		panic("missing transportationService")
+	}
+
+	if paymentsService == nil {
+		panic("missing paymentsService")
+	}
+
+	if bookingsRepository == nil {
+		panic("missing bookingsRepository")
+	}
+
+	if webhooksRepository == nil {
+		panic("missing webhooksRepository")
+	}
+
+	if webhookSender == nil {
+		panic("missing webhookSender")
+	}
+
+	if now == nil {
+		panic("missing now")
+	}
+
+	return Handler{
+		eventBus:              eventBus,
+		outbox:                outbox,
+		commandBus:            commandBus,
+		transportationService: transportationService,
+		paymentsService:       paymentsService,
+		bookingsRepository:    bookingsRepository,
+		webhooksRepository:    webhooksRepository,
+		webhookSender:         webhookSender,
+		webhooksConfig:        webhooksConfig,
+		reservationTTL:        reservations.TTL,
+		now:                   now,
+	}
+}
+
+// Outbox marshals events for repositories to store with the changes they're about.
+type Outbox interface {
+	OutboxMessage(ctx context.Context, event any) (entities.DelayedMessage, error)
+}
+
+type TransportationService interface {
+	BookTaxi(ctx context.Context, request entities.TaxiBookingRequest) (string, error)
+	CancelTaxiBooking(ctx context.Context, taxiBookingID string) error
+	BookFlight(ctx context.Context, request entities.FlightBookingRequest) ([]string, error)
+	CancelFlightTicket(ctx context.Context, flightTicketID string) error
+}
+
+type PaymentsService interface {
+	RefundPayment(ctx context.Context, refund entities.PaymentRefund) error
+}
+
+type BookingsRepository interface {
+	AddBooking(ctx context.Context, booking entities.Booking, outbox ...entities.DelayedMessage) error
+	RemoveBooking(ctx context.Context, bookingID string) error
+	BookingByID(ctx context.Context, bookingID string) (entities.Booking, error)
+	SetTaxiBookingID(ctx context.Context, bookingID string, taxiBookingID string) error
+	MarkTaxiCancelled(ctx context.Context, bookingID string) error
+}
+
+type WebhooksRepository interface {
+	ByID(ctx context.Context, webhookID string) (entities.Webhook, error)
+	Attempts(ctx context.Context, webhookID string, eventID string) ([]entities.WebhookAttempt, error)
+	RecordAttempt(ctx context.Context, attempt entities.WebhookAttempt, gaveUp bool, disableAfterFailures int) error
+}
+
+type WebhookSender interface {
+	SendWebhook(ctx context.Context, request entities.WebhookRequest) (int, error)
+}
+
+func (h Handler) publish(ctx context.Context, event any) error {
+	err := h.eventBus.Publish(ctx, event)
+	if err != nil {
+		return fmt.Errorf("failed to publish %s event: %w", cqrs.StructName(event), err)
+	}
+
+	return nil
+}
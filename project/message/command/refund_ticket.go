@@ -0,0 +1,24 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"tickets/entities"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+)
+
+func (h Handler) RefundTicket(ctx context.Context, command *entities.RefundTicket) error {
+	log.FromContext(ctx).Infof("Refunding ticket %s", command.TicketID)
+
+	err := h.paymentsService.RefundPayment(ctx, entities.PaymentRefund{
+		TicketID:       command.TicketID,
+		RefundReason:   "customer requested refund",
+		IdempotencyKey: "refund-ticket-" + command.TicketID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to refund ticket %s: %w", command.TicketID, err)
+	}
+
+	return nil
+}
@@ -10,9 +10,5 @@ import (
 func (h Handler) AppendToTracker(ctx context.Context, event *entities.TicketBookingConfirmed) error {
 	log.FromContext(ctx).Info("Appending ticket to the tracker")
 
-	return h.spreadsheetsService.AppendRow(
-		ctx,
-		"tickets-to-print",
-		[]string{event.TicketID, event.CustomerEmail, event.Price.Amount, event.Price.Currency},
-	)
+	return h.appendToSheet(ctx, event)
 }
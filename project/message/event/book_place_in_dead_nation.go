@@ -0,0 +1,44 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"tickets/entities"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+)
+
+func (h Handler) BookPlaceInDeadNation(ctx context.Context, event *entities.BookingMade) error {
+	show, err := h.showsRepository.ShowByID(ctx, event.ShowID)
+	if err != nil {
+		return fmt.Errorf("failed to get show %s: %w", event.ShowID, err)
+	}
+
+	if !show.ExternalProvider {
+		return nil
+	}
+
+	_, err = h.bookingsRepository.BookingByID(ctx, event.BookingID)
+	if errors.Is(err, entities.ErrBookingNotFound) {
+		log.FromContext(ctx).Info("Booking expired, not booking place in Dead Nation")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get booking %s: %w", event.BookingID, err)
+	}
+
+	log.FromContext(ctx).Info("Booking place in Dead Nation")
+
+	err = h.deadNationService.BookInDeadNation(ctx, entities.DeadNationBooking{
+		BookingID:         event.BookingID,
+		DeadNationEventID: *show.DeadNationID,
+		NumberOfTickets:   event.NumberOfTickets,
+		CustomerEmail:     event.CustomerEmail,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to book place in dead nation: %w", err)
+	}
+
+	return nil
+}
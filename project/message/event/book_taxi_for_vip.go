@@ -0,0 +1,29 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"tickets/entities"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+)
+
+func (h Handler) BookTaxiForVIP(ctx context.Context, event *entities.BookingMade) error {
+	if !event.VIP {
+		return nil
+	}
+
+	log.FromContext(ctx).Info("Requesting taxi for VIP booking")
+
+	err := h.commandBus.Send(ctx, entities.BookTaxi{
+		Header:             entities.NewEventHeader(),
+		BookingID:          event.BookingID,
+		CustomerEmail:      event.CustomerEmail,
+		NumberOfPassengers: event.NumberOfTickets,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send BookTaxi command: %w", err)
+	}
+
+	return nil
+}
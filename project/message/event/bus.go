@@ -1,20 +1,87 @@
 package event
 
 import (
+	"context"
+	"fmt"
+	"tickets/entities"
+	"time"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
 	"github.com/ThreeDotsLabs/watermill/components/cqrs"
 	"github.com/ThreeDotsLabs/watermill/message"
 )
 
-func NewEventBus(pub message.Publisher) *cqrs.EventBus {
+// AllEventsTopic receives every published event, as Redis streams can't be subscribed to by a pattern.
+// Events are forwarded from it to the topics named after them, where the event handlers consume them.
+const AllEventsTopic = "events"
+
+// Bus publishes events right away, or later with PublishAt and PublishAfter. Delayed events are stored
+// until DelayedRelay publishes them to the same topic, so handlers can't tell them apart from other events.
+type Bus struct {
+	*cqrs.EventBus
+
+	delayedMessages DelayedMessagesRepository
+	marshaler       cqrs.CommandEventMarshaler
+}
+
+func NewEventBus(pub message.Publisher, delayedMessages DelayedMessagesRepository) *Bus {
+	if delayedMessages == nil {
+		panic("missing delayedMessages")
+	}
+
+	marshaler := cqrs.JSONMarshaler{GenerateName: cqrs.StructName}
+
 	bus, err := cqrs.NewEventBusWithConfig(pub, cqrs.EventBusConfig{
+		// a single publish, so a failure can't leave the event in only some of the topics
 		GeneratePublishTopic: func(params cqrs.GenerateEventPublishTopicParams) (string, error) {
-			return params.EventName, nil
+			return AllEventsTopic, nil
 		},
-		Marshaler: cqrs.JSONMarshaler{GenerateName: cqrs.StructName},
+		Marshaler: marshaler,
 	})
 	if err != nil {
 		panic(err)
 	}
 
-	return bus
+	return &Bus{
+		EventBus:        bus,
+		delayedMessages: delayedMessages,
+		marshaler:       marshaler,
+	}
+}
+
+func (b *Bus) PublishAt(ctx context.Context, event any, publishAt time.Time) error {
+	msg, err := b.delayedMessage(ctx, event, publishAt)
+	if err != nil {
+		return err
+	}
+
+	return b.delayedMessages.Add(ctx, msg)
+}
+
+// OutboxMessage marshals the event for a repository to store in the transaction of the change the event is about,
+// it's published by DelayedRelay once the transaction is committed.
+func (b *Bus) OutboxMessage(ctx context.Context, event any) (entities.DelayedMessage, error) {
+	return b.delayedMessage(ctx, event, time.Now())
+}
+
+func (b *Bus) delayedMessage(ctx context.Context, event any, publishAt time.Time) (entities.DelayedMessage, error) {
+	msg, err := b.marshaler.Marshal(event)
+	if err != nil {
+		return entities.DelayedMessage{}, fmt.Errorf("failed to marshal %s event: %w", cqrs.StructName(event), err)
+	}
+
+	// the relay publishes without the caller's context
+	msg.Metadata.Set("correlation_id", log.CorrelationIDFromContext(ctx))
+
+	return entities.DelayedMessage{
+		MessageID: msg.UUID,
+		Topic:     AllEventsTopic,
+		Payload:   msg.Payload,
+		Metadata:  msg.Metadata,
+		PublishAt: publishAt.UTC(),
+	}, nil
+}
+
+func (b *Bus) PublishAfter(ctx context.Context, event any, delay time.Duration) error {
+	return b.PublishAt(ctx, event, time.Now().Add(delay))
 }
@@ -0,0 +1,53 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"tickets/entities"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+)
+
+// CalculateTicketRefund applies the refund policy of the ticket's show, as of the time the ticket was canceled.
+func (h Handler) CalculateTicketRefund(ctx context.Context, event *entities.TicketBookingCanceled) error {
+	canceledAt, err := event.Header.PublishedAtTime()
+	if err != nil {
+		return fmt.Errorf("failed to parse cancellation time: %w", err)
+	}
+
+	percent := 100
+	if event.BookingID != "" {
+		show, err := h.showForBooking(ctx, event.BookingID)
+		if errors.Is(err, entities.ErrBookingNotFound) {
+			log.FromContext(ctx).Warnf("Booking %s not found, refunding the full price", event.BookingID)
+		} else if err != nil {
+			return err
+		} else {
+			percent = show.RefundPolicy.Percent(show.StartTime, canceledAt)
+		}
+	}
+
+	refund, err := event.Price.Percent(percent)
+	if err != nil {
+		return fmt.Errorf("failed to calculate refund of ticket %s: %w", event.TicketID, err)
+	}
+
+	log.FromContext(ctx).Infof("Refunding %d%% of ticket %s: %s %s", percent, event.TicketID, refund.Amount, refund.Currency)
+
+	err = h.eventBus.Publish(ctx, entities.TicketRefundCalculated{
+		Header:        entities.NewEventHeader(),
+		TicketID:      event.TicketID,
+		BookingID:     event.BookingID,
+		CustomerEmail: event.CustomerEmail,
+		Locale:        event.Locale,
+		Price:         event.Price,
+		RefundPercent: percent,
+		Refund:        refund,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish TicketRefundCalculated event: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"tickets/entities"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+)
+
+// CancelDeadNationBooking compensates BookPlaceInDeadNation, the places of expired reservations are released there too.
+func (h Handler) CancelDeadNationBooking(ctx context.Context, event *entities.ReservationExpired) error {
+	show, err := h.showsRepository.ShowByID(ctx, event.ShowID)
+	if err != nil {
+		return fmt.Errorf("failed to get show %s: %w", event.ShowID, err)
+	}
+
+	if !show.ExternalProvider {
+		return nil
+	}
+
+	log.FromContext(ctx).Info("Cancelling place in Dead Nation of expired reservation")
+
+	err = h.deadNationService.CancelInDeadNation(ctx, event.BookingID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel place in dead nation: %w", err)
+	}
+
+	return nil
+}
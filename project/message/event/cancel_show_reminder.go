@@ -0,0 +1,17 @@
+package event
+
+import (
+	"context"
+	"tickets/entities"
+)
+
+func (h Handler) CancelShowReminder(ctx context.Context, event *entities.TicketBookingCanceled) error {
+	return h.showRemindersRepository.Update(
+		ctx,
+		event.TicketID,
+		func(ctx context.Context, reminder *entities.ShowReminder) error {
+			reminder.Canceled = true
+			return nil
+		},
+	)
+}
@@ -0,0 +1,43 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"tickets/entities"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+)
+
+// CancelTaxiForCanceledTicket compensates BookTaxiForVIP, the taxi of a VIP booking is canceled
+// with the first canceled ticket of the booking.
+func (h Handler) CancelTaxiForCanceledTicket(ctx context.Context, event *entities.TicketBookingCanceled) error {
+	if event.BookingID == "" {
+		return nil
+	}
+
+	booking, err := h.bookingsRepository.BookingByID(ctx, event.BookingID)
+	if errors.Is(err, entities.ErrBookingNotFound) {
+		log.FromContext(ctx).Warnf("Booking %s not found, not cancelling taxi", event.BookingID)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get booking %s: %w", event.BookingID, err)
+	}
+
+	if !booking.VIP {
+		return nil
+	}
+
+	log.FromContext(ctx).Info("Requesting taxi cancellation for canceled VIP ticket")
+
+	err = h.commandBus.Send(ctx, entities.CancelTaxiBooking{
+		Header:    entities.NewEventHeader(),
+		BookingID: event.BookingID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send CancelTaxiBooking command: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"tickets/entities"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+)
+
+// CancelTaxiForExpiredReservation compensates BookTaxiForVIP when the VIP booking is never confirmed.
+// The expired booking is removed, so its taxi is passed with the command.
+func (h Handler) CancelTaxiForExpiredReservation(ctx context.Context, event *entities.ReservationExpired) error {
+	if !event.VIP {
+		return nil
+	}
+
+	log.FromContext(ctx).Info("Requesting taxi cancellation for expired VIP reservation")
+
+	command := entities.CancelTaxiBooking{
+		Header:    entities.NewEventHeader(),
+		BookingID: event.BookingID,
+	}
+	if event.TaxiBookingID != nil {
+		command.TaxiBookingID = *event.TaxiBookingID
+	}
+
+	err := h.commandBus.Send(ctx, command)
+	if err != nil {
+		return fmt.Errorf("failed to send CancelTaxiBooking command: %w", err)
+	}
+
+	return nil
+}
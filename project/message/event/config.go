@@ -1,6 +1,8 @@
 package event
 
 import (
+	"slices"
+
 	"github.com/ThreeDotsLabs/watermill"
 	"github.com/ThreeDotsLabs/watermill-redisstream/pkg/redisstream"
 	"github.com/ThreeDotsLabs/watermill/components/cqrs"
@@ -8,16 +10,37 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
-func NewProcessorConfig(redisClient *redis.Client, logger watermill.LoggerAdapter) cqrs.EventProcessorConfig {
+// handlersFromLatest were added for events that already had a history, their consumer groups are created
+// at the end of the topic, so past events don't print tickets, email customers, refund payments
+// or release canceled tickets when they're deployed.
+var handlersFromLatest = []string{
+	"PrintTicket",
+	"SendConfirmationEmail",
+	"SendCancellationEmail",
+	"CalculateTicketRefund",
+	"RequestTicketRefund",
+	"OfferTicketsFreedByTicketCancellation",
+}
+
+func NewProcessorConfig(
+	redisClient *redis.Client,
+	consumerGroupPrefix string,
+	logger watermill.LoggerAdapter,
+) cqrs.EventProcessorConfig {
 	return cqrs.EventProcessorConfig{
 		GenerateSubscribeTopic: func(params cqrs.EventProcessorGenerateSubscribeTopicParams) (string, error) {
 			return params.EventName, nil
 		},
 		SubscriberConstructor: func(params cqrs.EventProcessorSubscriberConstructorParams) (message.Subscriber, error) {
-			return redisstream.NewSubscriber(redisstream.SubscriberConfig{
+			subscriberConfig := redisstream.SubscriberConfig{
 				Client:        redisClient,
-				ConsumerGroup: "svc.tickets" + params.HandlerName,
-			}, logger)
+				ConsumerGroup: consumerGroupPrefix + params.HandlerName,
+			}
+			if slices.Contains(handlersFromLatest, params.HandlerName) {
+				subscriberConfig.OldestId = "$"
+			}
+
+			return redisstream.NewSubscriber(subscriberConfig, logger)
 		},
 		Marshaler: cqrs.JSONMarshaler{GenerateName: cqrs.StructName},
 		Logger:    logger,
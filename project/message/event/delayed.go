@@ -0,0 +1,96 @@
+package event
+
+import (
+	"context"
+	"tickets/config"
+	"tickets/entities"
+	"time"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+type DelayedMessagesRepository interface {
+	Add(ctx context.Context, msg entities.DelayedMessage) error
+	ClaimDue(ctx context.Context, now time.Time, claimedUntil time.Time, limit int) ([]entities.DelayedMessage, error)
+	Remove(ctx context.Context, messageID string) error
+}
+
+// DelayedRelay publishes delayed messages when they're due. Each message is removed once it's published,
+// so only a message the relay failed to remove is published again, like any redelivered message.
+type DelayedRelay struct {
+	repository DelayedMessagesRepository
+	publisher  message.Publisher
+	cfg        config.Delayed
+}
+
+func NewDelayedRelay(repository DelayedMessagesRepository, pub message.Publisher, cfg config.Delayed) DelayedRelay {
+	if repository == nil {
+		panic("missing repository")
+	}
+
+	if pub == nil {
+		panic("missing publisher")
+	}
+
+	return DelayedRelay{
+		repository: repository,
+		publisher:  pub,
+		cfg:        cfg,
+	}
+}
+
+// Run publishes due messages until ctx is done.
+func (r DelayedRelay) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		// a full batch means there may be more due messages
+		for {
+			claimed, err := r.publishDue(context.WithoutCancel(ctx))
+			if err != nil {
+				log.FromContext(ctx).WithError(err).Error("Failed to publish delayed messages")
+				break
+			}
+			if claimed < r.cfg.BatchSize || ctx.Err() != nil {
+				break
+			}
+		}
+	}
+}
+
+// publishDue publishes a batch of due messages outside of the transaction claiming them,
+// messages that fail are left to be published again when their claim runs out.
+func (r DelayedRelay) publishDue(ctx context.Context) (int, error) {
+	now := time.Now().UTC()
+
+	msgs, err := r.repository.ClaimDue(ctx, now, now.Add(r.cfg.ClaimTimeout), r.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, delayed := range msgs {
+		msg := message.NewMessage(delayed.MessageID, delayed.Payload)
+		msg.Metadata = delayed.Metadata
+
+		err = r.publisher.Publish(delayed.Topic, msg)
+		if err != nil {
+			log.FromContext(ctx).WithError(err).Errorf("Failed to publish delayed message %s", delayed.MessageID)
+			continue
+		}
+
+		err = r.repository.Remove(ctx, delayed.MessageID)
+		if err != nil {
+			log.FromContext(ctx).WithError(err).Errorf("Failed to remove published delayed message %s", delayed.MessageID)
+		}
+	}
+
+	return len(msgs), nil
+}
@@ -2,18 +2,58 @@ package event
 
 import (
 	"context"
+	"tickets/config"
 	"tickets/entities"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
 )
 
 type Handler struct {
-	spreadsheetsService SpreadsheetsService
-	receiptsService     ReceiptsService
+	eventBus                *Bus
+	commandBus              *cqrs.CommandBus
+	spreadsheetsService     SpreadsheetsService
+	receiptsService         ReceiptsService
+	deadNationService       DeadNationService
+	filesService            FilesService
+	notificationsService    NotificationsService
+	showsRepository         ShowsRepository
+	bookingsRepository      BookingsRepository
+	showRemindersRepository ShowRemindersRepository
+	waitlistRepository      WaitlistRepository
+	sheetLayouts            map[string]sheetLayout
+	emailTemplates          emailTemplates
+	showReminderBefore      time.Duration
+	waitlistOfferTTL        time.Duration
+	now                     func() time.Time
 }
 
+// NewHandler creates the event handlers, notificationsService is optional (see SendsEmails).
 func NewHandler(
+	eventBus *Bus,
+	commandBus *cqrs.CommandBus,
 	spreadsheetsService SpreadsheetsService,
 	receiptsService ReceiptsService,
+	deadNationService DeadNationService,
+	filesService FilesService,
+	notificationsService NotificationsService,
+	showsRepository ShowsRepository,
+	bookingsRepository BookingsRepository,
+	showRemindersRepository ShowRemindersRepository,
+	waitlistRepository WaitlistRepository,
+	sheets config.Sheets,
+	notifications config.Notifications,
+	waitlist config.Waitlist,
+	now func() time.Time,
 ) Handler {
+	if eventBus == nil {
+		panic("missing eventBus")
+	}
+
+	if commandBus == nil {
+		panic("missing commandBus")
+	}
+
 	if spreadsheetsService == nil {
 		panic("missing spreadsheetsService")
 	}
@@ -22,12 +62,90 @@ func NewHandler(
 		panic("missing receiptsService")
 	}
 
+	if deadNationService == nil {
+		panic("missing deadNationService")
+	}
+
+	if filesService == nil {
+		panic("missing filesService")
+	}
+
+	if showsRepository == nil {
+		panic("missing showsRepository")
+	}
+
+	if bookingsRepository == nil {
+		panic("missing bookingsRepository")
+	}
+
+	if showRemindersRepository == nil {
+		panic("missing showRemindersRepository")
+	}
+
+	if waitlistRepository == nil {
+		panic("missing waitlistRepository")
+	}
+
+	if now == nil {
+		panic("missing now")
+	}
+
+	sheetLayouts, err := newSheetLayouts(sheets)
+	if err != nil {
+		panic(err)
+	}
+
+	err = validateSheetLayouts(
+		sheetLayouts,
+		&entities.TicketBookingConfirmed{},
+		&entities.TicketRefundCalculated{},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	emailTemplates, err := newEmailTemplates(notifications)
+	if err != nil {
+		panic(err)
+	}
+
+	err = validateEmailTemplates(
+		emailTemplates,
+		&entities.TicketBookingConfirmed{},
+		&entities.TicketRefundCalculated{},
+		&entities.ShowReminderDue{},
+		&entities.WaitlistOfferMade{},
+	)
+	if err != nil {
+		panic(err)
+	}
+
 	return Handler{
-		receiptsService:     receiptsService,
-		spreadsheetsService: spreadsheetsService,
+		eventBus:                eventBus,
+		commandBus:              commandBus,
+		receiptsService:         receiptsService,
+		spreadsheetsService:     spreadsheetsService,
+		deadNationService:       deadNationService,
+		filesService:            filesService,
+		notificationsService:    notificationsService,
+		showsRepository:         showsRepository,
+		bookingsRepository:      bookingsRepository,
+		showRemindersRepository: showRemindersRepository,
+		waitlistRepository:      waitlistRepository,
+		sheetLayouts:            sheetLayouts,
+		emailTemplates:          emailTemplates,
+		showReminderBefore:      notifications.ShowReminderBefore,
+		waitlistOfferTTL:        waitlist.OfferTTL,
+		now:                     now,
 	}
 }
 
+// SendsEmails tells if the handlers emailing customers should be registered,
+// they can't do anything without a notifications service.
+func (h Handler) SendsEmails() bool {
+	return h.notificationsService != nil
+}
+
 type SpreadsheetsService interface {
 	AppendRow(ctx context.Context, sheetName string, row []string) error
 }
@@ -35,3 +153,43 @@ type SpreadsheetsService interface {
 type ReceiptsService interface {
 	IssueReceipt(ctx context.Context, request entities.IssueReceiptRequest) (entities.IssueReceiptResponse, error)
 }
+
+type DeadNationService interface {
+	BookInDeadNation(ctx context.Context, booking entities.DeadNationBooking) error
+	CancelInDeadNation(ctx context.Context, bookingID string) error
+}
+
+type FilesService interface {
+	UploadFile(ctx context.Context, fileID string, content string) error
+}
+
+type NotificationsService interface {
+	SendEmail(ctx context.Context, email entities.Email) error
+}
+
+type ShowsRepository interface {
+	ShowByID(ctx context.Context, showID string) (entities.Show, error)
+}
+
+type BookingsRepository interface {
+	BookingByID(ctx context.Context, bookingID string) (entities.Booking, error)
+	ReleaseTicket(ctx context.Context, bookingID string, ticketID string) (string, error)
+}
+
+type ShowRemindersRepository interface {
+	Update(
+		ctx context.Context,
+		ticketID string,
+		updateFn func(ctx context.Context, reminder *entities.ShowReminder) error,
+	) error
+}
+
+type WaitlistRepository interface {
+	OfferNext(
+		ctx context.Context,
+		showID string,
+		offeredAt time.Time,
+		expiresAt time.Time,
+		outboxFn func(ctx context.Context, entry entities.WaitlistEntry) (entities.DelayedMessage, error),
+	) (bool, error)
+}
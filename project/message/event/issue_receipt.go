@@ -16,10 +16,21 @@ func (h Handler) IssueReceipt(ctx context.Context, event *entities.TicketBooking
 		Price:    event.Price,
 	}
 
-	_, err := h.receiptsService.IssueReceipt(ctx, request)
+	receipt, err := h.receiptsService.IssueReceipt(ctx, request)
 	if err != nil {
 		return fmt.Errorf("failed to issue receipt: %w", err)
 	}
 
+	err = h.eventBus.Publish(ctx, entities.TicketReceiptIssued{
+		Header:        entities.NewEventHeader(),
+		TicketID:      event.TicketID,
+		BookingID:     event.BookingID,
+		ReceiptNumber: receipt.ReceiptNumber,
+		IssuedAt:      receipt.IssuedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish TicketReceiptIssued event: %w", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,133 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+	"tickets/config"
+	"tickets/entities"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+)
+
+type emailTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+type emailTemplates struct {
+	defaultLocale string
+	// byEvent maps event names to templates per locale.
+	byEvent map[string]map[string]emailTemplate
+}
+
+func newEmailTemplates(notifications config.Notifications) (emailTemplates, error) {
+	templates := emailTemplates{
+		defaultLocale: notifications.DefaultLocale,
+		byEvent:       make(map[string]map[string]emailTemplate, len(notifications.Emails)),
+	}
+
+	for eventName, locales := range notifications.Emails {
+		templates.byEvent[eventName] = make(map[string]emailTemplate, len(locales))
+
+		for locale, email := range locales {
+			name := fmt.Sprintf("%s[%s]", eventName, locale)
+
+			subject, err := template.New(name + ".subject").Option("missingkey=error").Parse(email.Subject)
+			if err != nil {
+				return emailTemplates{}, fmt.Errorf("invalid email subject template for %s: %w", name, err)
+			}
+
+			body, err := template.New(name + ".body").Option("missingkey=error").Parse(email.Body)
+			if err != nil {
+				return emailTemplates{}, fmt.Errorf("invalid email body template for %s: %w", name, err)
+			}
+
+			templates.byEvent[eventName][locale] = emailTemplate{subject: subject, body: body}
+		}
+	}
+
+	return templates, nil
+}
+
+// validateEmailTemplates checks if the templates of all locales can be rendered for all events customers are notified about.
+func validateEmailTemplates(templates emailTemplates, events ...any) error {
+	ctx := log.ContextWithCorrelationID(context.Background(), "email-templates-validation")
+
+	for _, event := range events {
+		eventName := cqrs.StructName(event)
+
+		locales, ok := templates.byEvent[eventName]
+		if !ok {
+			return fmt.Errorf("no email template configured for %s", eventName)
+		}
+
+		for locale, tmpl := range locales {
+			_, err := tmpl.render(ctx, event)
+			if err != nil {
+				return fmt.Errorf("invalid email template for %s in locale %s: %w", eventName, locale, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (t emailTemplates) forEvent(eventName string, locale string) (emailTemplate, bool) {
+	locales, ok := t.byEvent[eventName]
+	if !ok {
+		return emailTemplate{}, false
+	}
+
+	if tmpl, ok := locales[locale]; ok {
+		return tmpl, true
+	}
+
+	tmpl, ok := locales[t.defaultLocale]
+	return tmpl, ok
+}
+
+func (t emailTemplate) render(ctx context.Context, event any) (entities.Email, error) {
+	data, err := eventTemplateData(ctx, event)
+	if err != nil {
+		return entities.Email{}, err
+	}
+
+	var subject, body strings.Builder
+
+	err = t.subject.Execute(&subject, data)
+	if err != nil {
+		return entities.Email{}, fmt.Errorf("failed to render email subject: %w", err)
+	}
+
+	err = t.body.Execute(&body, data)
+	if err != nil {
+		return entities.Email{}, fmt.Errorf("failed to render email body: %w", err)
+	}
+
+	return entities.Email{Subject: subject.String(), Body: body.String()}, nil
+}
+
+func (h Handler) notifyCustomer(ctx context.Context, event any, customerEmail string, locale string) error {
+	eventName := cqrs.StructName(event)
+
+	tmpl, ok := h.emailTemplates.forEvent(eventName, locale)
+	if !ok {
+		return fmt.Errorf("no email template configured for %s", eventName)
+	}
+
+	email, err := tmpl.render(ctx, event)
+	if err != nil {
+		return fmt.Errorf("failed to render email for %s: %w", eventName, err)
+	}
+	email.To = customerEmail
+
+	err = h.notificationsService.SendEmail(ctx, email)
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"tickets/entities"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+)
+
+func (h Handler) OfferTicketsFreedByExpiry(ctx context.Context, event *entities.ReservationExpired) error {
+	return h.offerFreedTickets(ctx, event.ShowID)
+}
+
+func (h Handler) OfferTicketsFreedByCancellation(ctx context.Context, event *entities.BookingCanceled) error {
+	if event.ShowID == "" {
+		return nil
+	}
+
+	return h.offerFreedTickets(ctx, event.ShowID)
+}
+
+func (h Handler) OfferTicketsFreedByTicketCancellation(ctx context.Context, event *entities.TicketBookingCanceled) error {
+	if event.BookingID == "" {
+		return nil
+	}
+
+	showID, err := h.bookingsRepository.ReleaseTicket(ctx, event.BookingID, event.TicketID)
+	if errors.Is(err, entities.ErrBookingNotFound) {
+		// tickets of removed bookings were released with them
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return h.offerFreedTickets(ctx, showID)
+}
+
+// offerFreedTickets offers the tickets to the customers waiting for them, in order, as long as there are tickets left.
+// Unclaimed offers expire like other reservations, which frees the tickets for the next customers.
+func (h Handler) offerFreedTickets(ctx context.Context, showID string) error {
+	for {
+		expiresAt := h.now().Add(h.waitlistOfferTTL).UTC()
+
+		offered, err := h.waitlistRepository.OfferNext(
+			ctx,
+			showID,
+			h.now().UTC(),
+			expiresAt,
+			func(ctx context.Context, entry entities.WaitlistEntry) (entities.DelayedMessage, error) {
+				log.FromContext(ctx).Infof("Offering %d tickets to waitlist entry %s", entry.NumberOfTickets, entry.EntryID)
+
+				return h.eventBus.OutboxMessage(ctx, entities.WaitlistOfferMade{
+					Header:          entities.NewEventHeader(),
+					EntryID:         entry.EntryID,
+					BookingID:       entry.EntryID,
+					ShowID:          entry.ShowID,
+					CustomerEmail:   entry.CustomerEmail,
+					NumberOfTickets: entry.NumberOfTickets,
+					ExpiresAt:       expiresAt,
+				})
+			},
+		)
+		if err != nil || !offered {
+			return err
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"tickets/entities"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+)
+
+func (h Handler) PrintTicket(ctx context.Context, event *entities.TicketBookingConfirmed) error {
+	log.FromContext(ctx).Info("Printing ticket")
+
+	ticket := ticketFile{
+		TicketID:      event.TicketID,
+		CustomerEmail: event.CustomerEmail,
+		Price:         event.Price,
+	}
+
+	if event.BookingID != "" {
+		show, err := h.showForBooking(ctx, event.BookingID)
+		if errors.Is(err, entities.ErrBookingNotFound) {
+			// retrying won't make the booking appear, the ticket is still worth printing
+			log.FromContext(ctx).Warnf("Booking %s not found, printing ticket without show details", event.BookingID)
+		} else if err != nil {
+			return err
+		} else {
+			ticket.Show = &show
+		}
+	}
+
+	content, err := renderTicketFile(ticket)
+	if err != nil {
+		return err
+	}
+
+	fileName := ticketFileName(event.TicketID)
+
+	err = h.filesService.UploadFile(ctx, fileName, content)
+	if err != nil {
+		return fmt.Errorf("failed to upload ticket file: %w", err)
+	}
+
+	err = h.eventBus.Publish(ctx, entities.TicketPrinted{
+		Header:    entities.NewEventHeader(),
+		TicketID:  event.TicketID,
+		BookingID: event.BookingID,
+		FileName:  fileName,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish TicketPrinted event: %w", err)
+	}
+
+	return nil
+}
+
+func (h Handler) showForBooking(ctx context.Context, bookingID string) (entities.Show, error) {
+	booking, err := h.bookingsRepository.BookingByID(ctx, bookingID)
+	if err != nil {
+		return entities.Show{}, fmt.Errorf("failed to get booking %s: %w", bookingID, err)
+	}
+
+	show, err := h.showsRepository.ShowByID(ctx, booking.ShowID)
+	if err != nil {
+		return entities.Show{}, fmt.Errorf("failed to get show %s: %w", booking.ShowID, err)
+	}
+
+	return show, nil
+}
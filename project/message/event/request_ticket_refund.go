@@ -0,0 +1,35 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"tickets/entities"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+)
+
+// RequestTicketRefund refunds the ticket's payment when its full price is refunded.
+// The payments gateway can only refund whole payments, so partial refunds are left to be made by hand.
+func (h Handler) RequestTicketRefund(ctx context.Context, event *entities.TicketRefundCalculated) error {
+	if !event.Refund.Positive() {
+		log.FromContext(ctx).Info("Nothing to refund")
+		return nil
+	}
+	if event.RefundPercent < 100 {
+		log.FromContext(ctx).Warnf(
+			"Payments can't be refunded partially, %s %s of ticket %s has to be refunded by hand",
+			event.Refund.Amount, event.Refund.Currency, event.TicketID,
+		)
+		return nil
+	}
+
+	err := h.commandBus.Send(ctx, entities.RefundTicket{
+		Header:   entities.NewEventHeader(),
+		TicketID: event.TicketID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send RefundTicket command: %w", err)
+	}
+
+	return nil
+}
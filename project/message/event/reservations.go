@@ -0,0 +1,104 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"tickets/config"
+	"tickets/entities"
+	"time"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+	"github.com/lithammer/shortuuid/v3"
+)
+
+const expiredReservationsBatchSize = 100
+
+type ReservationsRepository interface {
+	RemoveExpiredBookings(
+		ctx context.Context,
+		now time.Time,
+		limit int,
+		outboxFn func(ctx context.Context, booking entities.Booking) (entities.DelayedMessage, error),
+	) (int, error)
+}
+
+// ReservationsExpirer releases the tickets of bookings which weren't confirmed in time.
+type ReservationsExpirer struct {
+	repository ReservationsRepository
+	eventBus   *Bus
+	now        func() time.Time
+	cfg        config.Reservations
+}
+
+func NewReservationsExpirer(
+	repository ReservationsRepository,
+	eventBus *Bus,
+	now func() time.Time,
+	cfg config.Reservations,
+) ReservationsExpirer {
+	if repository == nil {
+		panic("missing repository")
+	}
+
+	if eventBus == nil {
+		panic("missing eventBus")
+	}
+
+	if now == nil {
+		panic("missing now")
+	}
+
+	return ReservationsExpirer{
+		repository: repository,
+		eventBus:   eventBus,
+		now:        now,
+		cfg:        cfg,
+	}
+}
+
+// Run expires reservations until ctx is done.
+func (e ReservationsExpirer) Run(ctx context.Context) error {
+	ticker := time.NewTicker(e.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		for {
+			expired, err := e.repository.RemoveExpiredBookings(
+				context.WithoutCancel(ctx),
+				e.now().UTC(),
+				expiredReservationsBatchSize,
+				e.expire,
+			)
+			if err != nil {
+				log.FromContext(ctx).WithError(err).Error("Failed to expire reservations")
+				break
+			}
+			if expired < expiredReservationsBatchSize || ctx.Err() != nil {
+				break
+			}
+		}
+	}
+}
+
+func (e ReservationsExpirer) expire(ctx context.Context, booking entities.Booking) (entities.DelayedMessage, error) {
+	ctx = log.ContextWithCorrelationID(ctx, fmt.Sprintf("gen_%s", shortuuid.New()))
+
+	log.FromContext(ctx).Infof("Reservation %s expired", booking.BookingID)
+
+	return e.eventBus.OutboxMessage(ctx, entities.ReservationExpired{
+		Header:          entities.NewEventHeader(),
+		BookingID:       booking.BookingID,
+		ShowID:          booking.ShowID,
+		NumberOfTickets: booking.NumberOfTickets,
+		CustomerEmail:   booking.CustomerEmail,
+		VIP:             booking.VIP,
+		TaxiBookingID:   booking.TaxiBookingID,
+		ExpiredAt:       *booking.ExpiresAt,
+	})
+}
@@ -0,0 +1,53 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"tickets/entities"
+	"time"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+)
+
+// ScheduleShowReminder schedules the reminder email sent by SendShowReminder before the show starts.
+func (h Handler) ScheduleShowReminder(ctx context.Context, event *entities.TicketBookingConfirmed) error {
+	if event.BookingID == "" {
+		return nil
+	}
+
+	show, err := h.showForBooking(ctx, event.BookingID)
+	if errors.Is(err, entities.ErrBookingNotFound) {
+		log.FromContext(ctx).Warnf("Booking %s not found, not scheduling show reminder", event.BookingID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !show.StartTime.After(time.Now()) {
+		return nil
+	}
+
+	// reminders of shows starting sooner than the configured time are sent right away
+	remindAt := show.StartTime.Add(-h.showReminderBefore)
+
+	log.FromContext(ctx).Infof("Scheduling show reminder at %s", remindAt.Format(time.RFC3339))
+
+	err = h.eventBus.PublishAt(ctx, entities.ShowReminderDue{
+		Header:        entities.NewEventHeader(),
+		TicketID:      event.TicketID,
+		BookingID:     event.BookingID,
+		CustomerEmail: event.CustomerEmail,
+		Locale:        event.Locale,
+		ShowID:        show.ShowID,
+		Title:         show.Title,
+		Venue:         show.Venue,
+		StartTime:     show.StartTime,
+	}, remindAt)
+	if err != nil {
+		return fmt.Errorf("failed to schedule ShowReminderDue event: %w", err)
+	}
+
+	return nil
+}
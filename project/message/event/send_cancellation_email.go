@@ -0,0 +1,14 @@
+package event
+
+import (
+	"context"
+	"tickets/entities"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+)
+
+func (h Handler) SendCancellationEmail(ctx context.Context, event *entities.TicketRefundCalculated) error {
+	log.FromContext(ctx).Info("Sending ticket cancellation email")
+
+	return h.notifyCustomer(ctx, event, event.CustomerEmail, event.Locale)
+}
@@ -0,0 +1,14 @@
+package event
+
+import (
+	"context"
+	"tickets/entities"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+)
+
+func (h Handler) SendConfirmationEmail(ctx context.Context, event *entities.TicketBookingConfirmed) error {
+	log.FromContext(ctx).Info("Sending ticket confirmation email")
+
+	return h.notifyCustomer(ctx, event, event.CustomerEmail, event.Locale)
+}
@@ -0,0 +1,82 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"tickets/entities"
+	"time"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+)
+
+// showReminderClaimDuration is how long other deliveries of the reminder wait for the email to be sent.
+const showReminderClaimDuration = time.Minute
+
+// SendShowReminder sends the reminder once per ticket, even if it was scheduled more than once
+// because TicketBookingConfirmed was redelivered.
+// The reminder is claimed before sending, so the email isn't sent while its row is locked.
+func (h Handler) SendShowReminder(ctx context.Context, event *entities.ShowReminderDue) error {
+	var claimed bool
+
+	err := h.showRemindersRepository.Update(
+		ctx,
+		event.TicketID,
+		func(ctx context.Context, reminder *entities.ShowReminder) error {
+			claimed = false
+
+			if reminder.Canceled {
+				log.FromContext(ctx).Info("Ticket canceled, not sending show reminder")
+				return nil
+			}
+			if reminder.SentAt != nil {
+				return nil
+			}
+
+			now := time.Now().UTC()
+			if reminder.ClaimedUntil != nil && reminder.ClaimedUntil.After(now) {
+				return fmt.Errorf("show reminder of ticket %s is being sent", event.TicketID)
+			}
+
+			claimedUntil := now.Add(showReminderClaimDuration)
+			reminder.ClaimedUntil = &claimedUntil
+			claimed = true
+
+			return nil
+		},
+	)
+	if err != nil || !claimed {
+		return err
+	}
+
+	log.FromContext(ctx).Info("Sending show reminder email")
+
+	err = h.notifyCustomer(ctx, event, event.CustomerEmail, event.Locale)
+	if err != nil {
+		// the redelivered message shouldn't wait for the claim to expire
+		releaseErr := h.showRemindersRepository.Update(
+			ctx,
+			event.TicketID,
+			func(ctx context.Context, reminder *entities.ShowReminder) error {
+				reminder.ClaimedUntil = nil
+				return nil
+			},
+		)
+		if releaseErr != nil {
+			log.FromContext(ctx).WithError(releaseErr).Warn("Failed to release show reminder claim")
+		}
+
+		return err
+	}
+
+	return h.showRemindersRepository.Update(
+		ctx,
+		event.TicketID,
+		func(ctx context.Context, reminder *entities.ShowReminder) error {
+			sentAt := time.Now().UTC()
+			reminder.SentAt = &sentAt
+			reminder.ClaimedUntil = nil
+
+			return nil
+		},
+	)
+}
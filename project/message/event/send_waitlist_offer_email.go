@@ -0,0 +1,14 @@
+package event
+
+import (
+	"context"
+	"tickets/entities"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+)
+
+func (h Handler) SendWaitlistOfferEmail(ctx context.Context, event *entities.WaitlistOfferMade) error {
+	log.FromContext(ctx).Info("Sending waitlist offer email")
+
+	return h.notifyCustomer(ctx, event, event.CustomerEmail, "")
+}
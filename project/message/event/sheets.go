@@ -0,0 +1,96 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+	"tickets/config"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+)
+
+type sheetLayout struct {
+	name    string
+	columns []*template.Template
+}
+
+func newSheetLayouts(sheets config.Sheets) (map[string]sheetLayout, error) {
+	layouts := make(map[string]sheetLayout, len(sheets))
+
+	for eventName, sheet := range sheets {
+		layout := sheetLayout{name: sheet.Name}
+
+		for i, column := range sheet.Columns {
+			tmpl, err := template.New(fmt.Sprintf("%s[%d]", eventName, i)).Option("missingkey=error").Parse(column)
+			if err != nil {
+				return nil, fmt.Errorf("invalid column template %q for %s: %w", column, eventName, err)
+			}
+
+			layout.columns = append(layout.columns, tmpl)
+		}
+
+		layouts[eventName] = layout
+	}
+
+	return layouts, nil
+}
+
+// validateSheetLayouts checks if the layouts can be rendered for all events appended to sheets.
+// All JSON fields of our events are present even for zero values, so a missing key means a typo in the template.
+func validateSheetLayouts(layouts map[string]sheetLayout, events ...any) error {
+	ctx := log.ContextWithCorrelationID(context.Background(), "sheet-layout-validation")
+
+	for _, event := range events {
+		eventName := cqrs.StructName(event)
+
+		layout, ok := layouts[eventName]
+		if !ok {
+			return fmt.Errorf("no sheet configured for %s", eventName)
+		}
+
+		_, err := layout.render(ctx, event)
+		if err != nil {
+			return fmt.Errorf("invalid sheet layout for %s: %w", eventName, err)
+		}
+	}
+
+	return nil
+}
+
+func (l sheetLayout) render(ctx context.Context, event any) ([]string, error) {
+	data, err := eventTemplateData(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+
+	row := make([]string, 0, len(l.columns))
+	for _, column := range l.columns {
+		var value strings.Builder
+		err := column.Execute(&value, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render column %s: %w", column.Name(), err)
+		}
+
+		row = append(row, value.String())
+	}
+
+	return row, nil
+}
+
+func (h Handler) appendToSheet(ctx context.Context, event any) error {
+	eventName := cqrs.StructName(event)
+
+	layout, ok := h.sheetLayouts[eventName]
+	if !ok {
+		return fmt.Errorf("no sheet configured for %s", eventName)
+	}
+
+	row, err := layout.render(ctx, event)
+	if err != nil {
+		return fmt.Errorf("failed to render row for sheet %s: %w", layout.name, err)
+	}
+
+	return h.spreadsheetsService.AppendRow(ctx, layout.name, row)
+}
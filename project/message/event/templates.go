@@ -0,0 +1,27 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+)
+
+// eventTemplateData returns the event's JSON fields and the correlation ID, for rendering configurable templates.
+func eventTemplateData(ctx context.Context, event any) (map[string]any, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	data := map[string]any{}
+	err = json.Unmarshal(payload, &data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+
+	data["correlation_id"] = log.CorrelationIDFromContext(ctx)
+
+	return data, nil
+}
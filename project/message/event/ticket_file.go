@@ -0,0 +1,49 @@
+package event
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"tickets/entities"
+)
+
+var ticketFileTemplate = template.Must(template.New("ticket").Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<title>Ticket {{.TicketID}}</title>
+</head>
+<body>
+	<h1>Ticket {{.TicketID}}</h1>
+	{{- with .Show}}
+	<h2>{{.Title}}</h2>
+	<p>Venue: {{.Venue}}</p>
+	<p>Starts at: {{.StartTime.Format "2006-01-02 15:04 MST"}}</p>
+	{{- end}}
+	<p>Customer: {{.CustomerEmail}}</p>
+	<p>Price: {{.Price.Amount}} {{.Price.Currency}}</p>
+</body>
+</html>
+`))
+
+type ticketFile struct {
+	TicketID      string
+	CustomerEmail string
+	Price         entities.Money
+	// Show is nil when the ticket isn't linked to a booking of a known show.
+	Show *entities.Show
+}
+
+func ticketFileName(ticketID string) string {
+	return fmt.Sprintf("%s-ticket.html", ticketID)
+}
+
+func renderTicketFile(ticket ticketFile) (string, error) {
+	var buf bytes.Buffer
+	err := ticketFileTemplate.Execute(&buf, ticket)
+	if err != nil {
+		return "", fmt.Errorf("failed to render ticket file: %w", err)
+	}
+
+	return buf.String(), nil
+}
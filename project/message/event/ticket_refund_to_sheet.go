@@ -7,12 +7,8 @@ import (
 	"github.com/ThreeDotsLabs/go-event-driven/common/log"
 )
 
-func (h Handler) CancelTicket(ctx context.Context, event *entities.TicketBookingCanceled) error {
+func (h Handler) CancelTicket(ctx context.Context, event *entities.TicketRefundCalculated) error {
 	log.FromContext(ctx).Info("Adding ticket refund to sheet")
 
-	return h.spreadsheetsService.AppendRow(
-		ctx,
-		"tickets-to-refund",
-		[]string{event.TicketID, event.CustomerEmail, event.Price.Amount, event.Price.Currency},
-	)
+	return h.appendToSheet(ctx, event)
 }
@@ -0,0 +1,49 @@
+package message
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"tickets/entities"
+
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+type EventsRepository interface {
+	AddEvent(ctx context.Context, event entities.StoredEvent) error
+}
+
+func storeEvent(eventsRepository EventsRepository) message.NoPublishHandlerFunc {
+	return func(msg *message.Message) error {
+		event, err := storedEventFromMessage(msg)
+		if err != nil {
+			return err
+		}
+
+		return eventsRepository.AddEvent(msg.Context(), event)
+	}
+}
+
+func storedEventFromMessage(msg *message.Message) (entities.StoredEvent, error) {
+	var event struct {
+		Header entities.EventHeader `json:"header"`
+	}
+	err := json.Unmarshal(msg.Payload, &event)
+	if err != nil {
+		return entities.StoredEvent{}, fmt.Errorf("failed to unmarshal event header: %w", err)
+	}
+
+	publishedAt, err := event.Header.PublishedAtTime()
+	if err != nil {
+		return entities.StoredEvent{}, fmt.Errorf("failed to parse published_at of event %s: %w", event.Header.ID, err)
+	}
+
+	return entities.StoredEvent{
+		EventID:       event.Header.ID,
+		EventName:     cqrs.JSONMarshaler{GenerateName: cqrs.StructName}.NameFromMessage(msg),
+		PublishedAt:   publishedAt,
+		CorrelationID: msg.Metadata.Get("correlation_id"),
+		Payload:       json.RawMessage(msg.Payload),
+	}, nil
+}
@@ -0,0 +1,28 @@
+package message
+
+import (
+	"fmt"
+
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+// forwardEvents publishes events from event.AllEventsTopic to the topics named after them,
+// which the event processor's handlers are subscribed to.
+func forwardEvents(pub message.Publisher) message.NoPublishHandlerFunc {
+	marshaler := cqrs.JSONMarshaler{GenerateName: cqrs.StructName}
+
+	return func(msg *message.Message) error {
+		eventName := marshaler.NameFromMessage(msg)
+		if eventName == "" {
+			return fmt.Errorf("message %s has no event name", msg.UUID)
+		}
+
+		err := pub.Publish(eventName, msg.Copy())
+		if err != nil {
+			return fmt.Errorf("failed to forward %s event: %w", eventName, err)
+		}
+
+		return nil
+	}
+}
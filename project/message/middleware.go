@@ -1,8 +1,9 @@
 package message
 
 import (
+	"context"
 	"fmt"
-	"time"
+	"tickets/config"
 
 	"github.com/ThreeDotsLabs/go-event-driven/common/log"
 	"github.com/ThreeDotsLabs/watermill"
@@ -12,19 +13,30 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-func useMiddlewares(router *message.Router, watermillLogger watermill.LoggerAdapter) {
+func useMiddlewares(router *message.Router, retryConfig config.Retry, watermillLogger watermill.LoggerAdapter) {
 	router.AddMiddleware(middleware.Recoverer)
 
 	router.AddMiddleware(middleware.Retry{
-		MaxRetries:      10,
-		InitialInterval: time.Millisecond * 100,
-		MaxInterval:     time.Second,
-		Multiplier:      2,
+		MaxRetries:      retryConfig.MaxRetries,
+		InitialInterval: retryConfig.InitialInterval,
+		MaxInterval:     retryConfig.MaxInterval,
+		Multiplier:      retryConfig.Multiplier,
 		Logger:          watermillLogger,
 	}.Middleware)
 
 	router.AddMiddleware(correlationIDMiddleware)
 	router.AddMiddleware(loggingMiddleware)
+	router.AddMiddleware(drainMiddleware)
+}
+
+// drainMiddleware detaches the handler's context from the subscriber's cancellation.
+// When the router is closing, in-flight handlers can finish their calls until the router's CloseTimeout,
+// while the Retry middleware (which still sees the original context) stops retrying.
+func drainMiddleware(next message.HandlerFunc) message.HandlerFunc {
+	return func(msg *message.Message) ([]*message.Message, error) {
+		msg.SetContext(context.WithoutCancel(msg.Context()))
+		return next(msg)
+	}
 }
 
 func loggingMiddleware(next message.HandlerFunc) message.HandlerFunc {
@@ -0,0 +1,116 @@
+package projection
+
+import (
+	"context"
+	"fmt"
+	"tickets/entities"
+	"time"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+)
+
+// OpsBookings keeps the OpsBooking read model up to date. Timestamps come from the events' headers,
+// so handling an event again (for example when replaying) leaves the read model unchanged.
+type OpsBookings struct {
+	repository OpsBookingsRepository
+}
+
+type OpsBookingsRepository interface {
+	Update(
+		ctx context.Context,
+		bookingID string,
+		updateFn func(ctx context.Context, opsBooking *entities.OpsBooking) error,
+	) error
+}
+
+func NewOpsBookings(repository OpsBookingsRepository) OpsBookings {
+	if repository == nil {
+		panic("missing repository")
+	}
+
+	return OpsBookings{repository: repository}
+}
+
+func (p OpsBookings) OnBookingMade(ctx context.Context, event *entities.BookingMade) error {
+	bookedAt, err := event.Header.PublishedAtTime()
+	if err != nil {
+		return fmt.Errorf("failed to parse published_at: %w", err)
+	}
+
+	return p.repository.Update(ctx, event.BookingID, func(ctx context.Context, opsBooking *entities.OpsBooking) error {
+		opsBooking.BookedAt = &bookedAt
+		opsBooking.ShowID = event.ShowID
+		opsBooking.NumberOfTickets = event.NumberOfTickets
+		opsBooking.CustomerEmail = event.CustomerEmail
+		opsBooking.VIP = event.VIP
+
+		return nil
+	})
+}
+
+func (p OpsBookings) OnTicketBookingConfirmed(ctx context.Context, event *entities.TicketBookingConfirmed) error {
+	return p.updateTicket(ctx, event.Header, event.BookingID, event.TicketID, func(ticket *entities.OpsTicket, at time.Time) {
+		ticket.Price = event.Price
+		ticket.CustomerEmail = event.CustomerEmail
+		ticket.ConfirmedAt = &at
+	})
+}
+
+func (p OpsBookings) OnTicketBookingCanceled(ctx context.Context, event *entities.TicketBookingCanceled) error {
+	return p.updateTicket(ctx, event.Header, event.BookingID, event.TicketID, func(ticket *entities.OpsTicket, at time.Time) {
+		ticket.CanceledAt = &at
+	})
+}
+
+// OnTicketRefundCalculated marks the ticket as refunded, unless the refund policy refunds nothing.
+func (p OpsBookings) OnTicketRefundCalculated(ctx context.Context, event *entities.TicketRefundCalculated) error {
+	if !event.Refund.Positive() {
+		return nil
+	}
+
+	return p.updateTicket(ctx, event.Header, event.BookingID, event.TicketID, func(ticket *entities.OpsTicket, at time.Time) {
+		ticket.RefundedAt = &at
+	})
+}
+
+func (p OpsBookings) OnTicketPrinted(ctx context.Context, event *entities.TicketPrinted) error {
+	return p.updateTicket(ctx, event.Header, event.BookingID, event.TicketID, func(ticket *entities.OpsTicket, at time.Time) {
+		ticket.PrintedAt = &at
+		ticket.PrintedFileName = event.FileName
+	})
+}
+
+func (p OpsBookings) OnTicketReceiptIssued(ctx context.Context, event *entities.TicketReceiptIssued) error {
+	return p.updateTicket(ctx, event.Header, event.BookingID, event.TicketID, func(ticket *entities.OpsTicket, _ time.Time) {
+		issuedAt := event.IssuedAt.UTC()
+		ticket.ReceiptIssuedAt = &issuedAt
+		ticket.ReceiptNumber = event.ReceiptNumber
+	})
+}
+
+func (p OpsBookings) updateTicket(
+	ctx context.Context,
+	header entities.EventHeader,
+	bookingID string,
+	ticketID string,
+	updateFn func(ticket *entities.OpsTicket, at time.Time),
+) error {
+	if bookingID == "" {
+		// tickets sold before bookings were tracked don't belong to any booking
+		log.FromContext(ctx).Infof("Ticket %s has no booking, skipping", ticketID)
+		return nil
+	}
+
+	at, err := header.PublishedAtTime()
+	if err != nil {
+		return fmt.Errorf("failed to parse published_at: %w", err)
+	}
+
+	return p.repository.Update(ctx, bookingID, func(ctx context.Context, opsBooking *entities.OpsBooking) error {
+		ticket := opsBooking.Tickets[ticketID]
+		updateFn(&ticket, at)
+		opsBooking.Tickets[ticketID] = ticket
+
+		return nil
+	})
+}
@@ -1,6 +1,8 @@
 package message
 
 import (
+	"tickets/config"
+
 	"github.com/ThreeDotsLabs/watermill"
 	"github.com/ThreeDotsLabs/watermill-redisstream/pkg/redisstream"
 	"github.com/ThreeDotsLabs/watermill/message"
@@ -19,8 +21,36 @@ func NewRedisPublisher(rdb *redis.Client, watermillLogger watermill.LoggerAdapte
 	return pub
 }
 
-func NewRedisClient(addr string) *redis.Client {
+func NewRedisClient(cfg config.Redis) *redis.Client {
 	return redis.NewClient(&redis.Options{
-		Addr: addr,
+		Addr:     cfg.Addr,
+		PoolSize: cfg.PoolSize,
 	})
 }
+
+func NewRedisSubscriber(rdb *redis.Client, consumerGroup string, watermillLogger watermill.LoggerAdapter) message.Subscriber {
+	sub, err := redisstream.NewSubscriber(redisstream.SubscriberConfig{
+		Client:        rdb,
+		ConsumerGroup: consumerGroup,
+	}, watermillLogger)
+	if err != nil {
+		panic(err)
+	}
+
+	return sub
+}
+
+// NewRedisSubscriberFromLatest creates its consumer group at the end of the stream, for consumers added
+// to a stream whose history was already handled in another way.
+func NewRedisSubscriberFromLatest(rdb *redis.Client, consumerGroup string, watermillLogger watermill.LoggerAdapter) message.Subscriber {
+	sub, err := redisstream.NewSubscriber(redisstream.SubscriberConfig{
+		Client:        rdb,
+		ConsumerGroup: consumerGroup,
+		OldestId:      "$",
+	}, watermillLogger)
+	if err != nil {
+		panic(err)
+	}
+
+	return sub
+}
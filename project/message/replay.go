@@ -0,0 +1,92 @@
+package message
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"tickets/entities"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+	"github.com/sirupsen/logrus"
+)
+
+// Projection is a group of event handlers that only update a read model, so it can be rebuilt from the event store.
+type Projection struct {
+	Name     string
+	Handlers []cqrs.EventHandler
+}
+
+type EventsStore interface {
+	ForEachEvent(ctx context.Context, fn func(ctx context.Context, event entities.StoredEvent) error) error
+}
+
+// EventReplayer feeds stored events directly into a projection, so nothing is republished
+// and handlers with side effects (which are never registered as projections) don't run.
+type EventReplayer struct {
+	eventsStore EventsStore
+	projections map[string]Projection
+}
+
+func NewEventReplayer(eventsStore EventsStore, projections []Projection) EventReplayer {
+	if eventsStore == nil {
+		panic("eventsStore is nil")
+	}
+
+	byName := make(map[string]Projection, len(projections))
+	for _, projection := range projections {
+		byName[projection.Name] = projection
+	}
+
+	return EventReplayer{
+		eventsStore: eventsStore,
+		projections: byName,
+	}
+}
+
+// Replay handles all stored events the projection is interested in, in order, and returns how many were replayed.
+// Projections must be idempotent, as they may see events they already handled live.
+func (r EventReplayer) Replay(ctx context.Context, projectionName string) (int, error) {
+	projection, ok := r.projections[projectionName]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", entities.ErrProjectionNotFound, projectionName)
+	}
+
+	handlersByEvent := map[string][]cqrs.EventHandler{}
+	for _, handler := range projection.Handlers {
+		eventName := cqrs.StructName(handler.NewEvent())
+		handlersByEvent[eventName] = append(handlersByEvent[eventName], handler)
+	}
+
+	replayed := 0
+	err := r.eventsStore.ForEachEvent(ctx, func(ctx context.Context, storedEvent entities.StoredEvent) error {
+		handlers := handlersByEvent[storedEvent.EventName]
+		if len(handlers) == 0 {
+			return nil
+		}
+
+		ctx = log.ToContext(ctx, logrus.WithField("correlation_id", storedEvent.CorrelationID))
+		ctx = log.ContextWithCorrelationID(ctx, storedEvent.CorrelationID)
+
+		for _, handler := range handlers {
+			event := handler.NewEvent()
+			err := json.Unmarshal(storedEvent.Payload, event)
+			if err != nil {
+				return fmt.Errorf("failed to unmarshal event %s: %w", storedEvent.EventID, err)
+			}
+
+			err = handler.Handle(ctx, event)
+			if err != nil {
+				return fmt.Errorf("failed to replay event %s into %s: %w", storedEvent.EventID, handler.HandlerName(), err)
+			}
+		}
+
+		replayed++
+		return nil
+	})
+	if err != nil {
+		return replayed, err
+	}
+
+	return replayed, nil
+}
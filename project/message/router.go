@@ -2,19 +2,73 @@ package message
 
 import (
 	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+	"tickets/config"
+	"tickets/message/command"
 	"tickets/message/event"
+	"tickets/message/saga"
+	"time"
 
 	"github.com/ThreeDotsLabs/watermill"
 	"github.com/ThreeDotsLabs/watermill/message"
 )
 
-func NewWatermillRouter(handler event.Handler, processorConfig cqrs.EventProcessorConfig, watermillLogger watermill.LoggerAdapter) *message.Router {
-	router, err := message.NewRouter(message.RouterConfig{}, watermillLogger)
+func NewWatermillRouter(
+	handler event.Handler,
+	processorConfig cqrs.EventProcessorConfig,
+	commandHandler command.Handler,
+	commandProcessorConfig cqrs.CommandProcessorConfig,
+	vipBundleProcessManager saga.VipBundleProcessManager,
+	projections []Projection,
+	eventsRepository EventsRepository,
+	eventStoreSubscriber message.Subscriber,
+	ticketsStream *TicketsStream,
+	ticketsStreamSubscriber message.Subscriber,
+	webhooksRepository WebhooksRepository,
+	webhooksBookingsRepository WebhooksBookingsRepository,
+	commandBus *cqrs.CommandBus,
+	webhooksSubscriber message.Subscriber,
+	forwarderSubscriber message.Subscriber,
+	forwarderPublisher message.Publisher,
+	retryConfig config.Retry,
+	closeTimeout time.Duration,
+	watermillLogger watermill.LoggerAdapter,
+) *message.Router {
+	router, err := message.NewRouter(message.RouterConfig{
+		CloseTimeout: closeTimeout,
+	}, watermillLogger)
 	if err != nil {
 		panic(err)
 	}
 
-	useMiddlewares(router, watermillLogger)
+	useMiddlewares(router, retryConfig, watermillLogger)
+
+	router.AddNoPublisherHandler(
+		"ForwardEvents",
+		event.AllEventsTopic,
+		forwarderSubscriber,
+		forwardEvents(forwarderPublisher),
+	)
+
+	router.AddNoPublisherHandler(
+		"StoreEvent",
+		event.AllEventsTopic,
+		eventStoreSubscriber,
+		storeEvent(eventsRepository),
+	)
+
+	router.AddNoPublisherHandler(
+		"TicketsStream",
+		event.AllEventsTopic,
+		ticketsStreamSubscriber,
+		ticketsStream.handle,
+	)
+
+	router.AddNoPublisherHandler(
+		"FanOutWebhooks",
+		event.AllEventsTopic,
+		webhooksSubscriber,
+		fanOutWebhooks(webhooksRepository, webhooksBookingsRepository, commandBus),
+	)
 
 	eventProcessor, err := cqrs.NewEventProcessorWithConfig(router, processorConfig)
 	if err != nil {
@@ -24,7 +78,69 @@ func NewWatermillRouter(handler event.Handler, processorConfig cqrs.EventProcess
 	err = eventProcessor.AddHandlers(
 		cqrs.NewEventHandler("AppendToTracker", handler.AppendToTracker),
 		cqrs.NewEventHandler("IssueReceipt", handler.IssueReceipt),
+		cqrs.NewEventHandler("PrintTicket", handler.PrintTicket),
+		cqrs.NewEventHandler("CalculateTicketRefund", handler.CalculateTicketRefund),
 		cqrs.NewEventHandler("CancelTicket", handler.CancelTicket),
+		cqrs.NewEventHandler("RequestTicketRefund", handler.RequestTicketRefund),
+		cqrs.NewEventHandler("BookPlaceInDeadNation", handler.BookPlaceInDeadNation),
+		cqrs.NewEventHandler("CancelDeadNationBooking", handler.CancelDeadNationBooking),
+		cqrs.NewEventHandler("OfferTicketsFreedByExpiry", handler.OfferTicketsFreedByExpiry),
+		cqrs.NewEventHandler("OfferTicketsFreedByCancellation", handler.OfferTicketsFreedByCancellation),
+		cqrs.NewEventHandler("OfferTicketsFreedByTicketCancellation", handler.OfferTicketsFreedByTicketCancellation),
+		cqrs.NewEventHandler("ScheduleShowReminder", handler.ScheduleShowReminder),
+		cqrs.NewEventHandler("CancelShowReminder", handler.CancelShowReminder),
+		cqrs.NewEventHandler("BookTaxiForVIP", handler.BookTaxiForVIP),
+		cqrs.NewEventHandler("CancelTaxiForCanceledTicket", handler.CancelTaxiForCanceledTicket),
+		cqrs.NewEventHandler("CancelTaxiForExpiredReservation", handler.CancelTaxiForExpiredReservation),
+
+		cqrs.NewEventHandler("VipBundle.OnVipBundleInitialized", vipBundleProcessManager.OnVipBundleInitialized),
+		cqrs.NewEventHandler("VipBundle.OnBookingMade", vipBundleProcessManager.OnBookingMade),
+		cqrs.NewEventHandler("VipBundle.OnBookingFailed", vipBundleProcessManager.OnBookingFailed),
+		cqrs.NewEventHandler("VipBundle.OnFlightBooked", vipBundleProcessManager.OnFlightBooked),
+		cqrs.NewEventHandler("VipBundle.OnFlightBookingFailed", vipBundleProcessManager.OnFlightBookingFailed),
+		cqrs.NewEventHandler("VipBundle.OnTaxiBooked", vipBundleProcessManager.OnTaxiBooked),
+		cqrs.NewEventHandler("VipBundle.OnTaxiBookingFailed", vipBundleProcessManager.OnTaxiBookingFailed),
+		cqrs.NewEventHandler("VipBundle.OnFlightTicketsCanceled", vipBundleProcessManager.OnFlightTicketsCanceled),
+		cqrs.NewEventHandler("VipBundle.OnBookingCanceled", vipBundleProcessManager.OnBookingCanceled),
+		cqrs.NewEventHandler("VipBundle.OnReservationExpired", vipBundleProcessManager.OnReservationExpired),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	if handler.SendsEmails() {
+		err = eventProcessor.AddHandlers(
+			cqrs.NewEventHandler("SendConfirmationEmail", handler.SendConfirmationEmail),
+			cqrs.NewEventHandler("SendCancellationEmail", handler.SendCancellationEmail),
+			cqrs.NewEventHandler("SendWaitlistOfferEmail", handler.SendWaitlistOfferEmail),
+			cqrs.NewEventHandler("SendShowReminder", handler.SendShowReminder),
+		)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	for _, projection := range projections {
+		err = eventProcessor.AddHandlers(projection.Handlers...)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	commandProcessor, err := cqrs.NewCommandProcessorWithConfig(router, commandProcessorConfig)
+	if err != nil {
+		panic(err)
+	}
+
+	err = commandProcessor.AddHandlers(
+		cqrs.NewCommandHandler("BookTaxi", commandHandler.BookTaxi),
+		cqrs.NewCommandHandler("CancelTaxiBooking", commandHandler.CancelTaxiBooking),
+		cqrs.NewCommandHandler("BookShowTickets", commandHandler.BookShowTickets),
+		cqrs.NewCommandHandler("CancelBooking", commandHandler.CancelBooking),
+		cqrs.NewCommandHandler("RefundTicket", commandHandler.RefundTicket),
+		cqrs.NewCommandHandler("BookFlight", commandHandler.BookFlight),
+		cqrs.NewCommandHandler("CancelFlightTickets", commandHandler.CancelFlightTickets),
+		cqrs.NewCommandHandler("DeliverWebhook", commandHandler.DeliverWebhook),
 	)
 	if err != nil {
 		panic(err)
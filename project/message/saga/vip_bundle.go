@@ -0,0 +1,327 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"tickets/entities"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+)
+
+// VipBundleProcessManager books the parts of a VIP bundle one by one: show tickets, the inbound flight,
+// the return flight and a taxi. When a step fails, the completed steps are compensated one by one
+// in reverse order, each compensation waiting for the previous one to finish.
+//
+// The bundle state is the only source of truth for the next step, so all handlers ignore events
+// of steps that were already recorded (for example redelivered ones) and events of other bookings.
+//
+// Commands and events following a bundle update are stored in the outbox with the update.
+type VipBundleProcessManager struct {
+	commandBus    *cqrs.CommandBus
+	commandOutbox Outbox
+	eventOutbox   Outbox
+	repository    VipBundleRepository
+}
+
+// Outbox marshals commands or events for the repository to store with the bundle.
+type Outbox interface {
+	OutboxMessage(ctx context.Context, message any) (entities.DelayedMessage, error)
+}
+
+type VipBundleRepository interface {
+	ByID(ctx context.Context, vipBundleID string) (entities.VipBundle, error)
+	UpdateByBookingID(
+		ctx context.Context,
+		bookingID string,
+		updateFn func(ctx context.Context, vipBundle *entities.VipBundle) ([]entities.DelayedMessage, error),
+	) error
+}
+
+func NewVipBundleProcessManager(
+	commandBus *cqrs.CommandBus,
+	commandOutbox Outbox,
+	eventOutbox Outbox,
+	repository VipBundleRepository,
+) VipBundleProcessManager {
+	if commandBus == nil {
+		panic("missing commandBus")
+	}
+
+	if commandOutbox == nil {
+		panic("missing commandOutbox")
+	}
+
+	if eventOutbox == nil {
+		panic("missing eventOutbox")
+	}
+
+	if repository == nil {
+		panic("missing repository")
+	}
+
+	return VipBundleProcessManager{
+		commandBus:    commandBus,
+		commandOutbox: commandOutbox,
+		eventOutbox:   eventOutbox,
+		repository:    repository,
+	}
+}
+
+func (p VipBundleProcessManager) OnVipBundleInitialized(ctx context.Context, event *entities.VipBundleInitialized) error {
+	vipBundle, err := p.repository.ByID(ctx, event.VipBundleID)
+	if err != nil {
+		return err
+	}
+
+	log.FromContext(ctx).Infof("Starting VIP bundle %s", vipBundle.VipBundleID)
+
+	// nothing is stored before the tickets are booked, so the command can be sent right away
+	err = p.commandBus.Send(ctx, entities.BookShowTickets{
+		Header:          entities.NewEventHeader(),
+		BookingID:       vipBundle.BookingID,
+		ShowID:          vipBundle.ShowID,
+		NumberOfTickets: vipBundle.NumberOfTickets,
+		CustomerEmail:   vipBundle.CustomerEmail,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send BookShowTickets command: %w", err)
+	}
+
+	return nil
+}
+
+func (p VipBundleProcessManager) OnBookingMade(ctx context.Context, event *entities.BookingMade) error {
+	return p.update(ctx, event.BookingID, func(ctx context.Context, vipBundle *entities.VipBundle) ([]entities.DelayedMessage, error) {
+		if vipBundle.BookingMade {
+			return nil, nil
+		}
+
+		vipBundle.BookingMade = true
+
+		return p.next(ctx, vipBundle)
+	})
+}
+
+func (p VipBundleProcessManager) OnFlightBooked(ctx context.Context, event *entities.FlightBooked) error {
+	return p.update(ctx, event.BookingID, func(ctx context.Context, vipBundle *entities.VipBundle) ([]entities.DelayedMessage, error) {
+		switch event.FlightID {
+		case vipBundle.InboundFlightID:
+			if vipBundle.InboundFlightTicketIDs != nil {
+				return nil, nil
+			}
+			vipBundle.InboundFlightTicketIDs = event.TicketIDs
+		case vipBundle.ReturnFlightID:
+			if vipBundle.ReturnFlightTicketIDs != nil {
+				return nil, nil
+			}
+			vipBundle.ReturnFlightTicketIDs = event.TicketIDs
+		default:
+			return nil, nil
+		}
+
+		return p.next(ctx, vipBundle)
+	})
+}
+
+func (p VipBundleProcessManager) OnTaxiBooked(ctx context.Context, event *entities.TaxiBooked) error {
+	return p.update(ctx, event.BookingID, func(ctx context.Context, vipBundle *entities.VipBundle) ([]entities.DelayedMessage, error) {
+		if vipBundle.TaxiBookingID != "" {
+			return nil, nil
+		}
+
+		vipBundle.TaxiBookingID = event.TaxiBookingID
+
+		return p.next(ctx, vipBundle)
+	})
+}
+
+func (p VipBundleProcessManager) OnBookingFailed(ctx context.Context, event *entities.BookingFailed) error {
+	return p.fail(ctx, event.BookingID, event.FailureReason)
+}
+
+func (p VipBundleProcessManager) OnFlightBookingFailed(ctx context.Context, event *entities.FlightBookingFailed) error {
+	return p.fail(ctx, event.BookingID, fmt.Sprintf("flight %s: %s", event.FlightID, event.FailureReason))
+}
+
+func (p VipBundleProcessManager) OnTaxiBookingFailed(ctx context.Context, event *entities.TaxiBookingFailed) error {
+	return p.fail(ctx, event.BookingID, event.FailureReason)
+}
+
+func (p VipBundleProcessManager) OnFlightTicketsCanceled(ctx context.Context, event *entities.FlightTicketsCanceled) error {
+	return p.update(ctx, event.BookingID, func(ctx context.Context, vipBundle *entities.VipBundle) ([]entities.DelayedMessage, error) {
+		switch event.FlightID {
+		case vipBundle.InboundFlightID:
+			if vipBundle.InboundFlightCanceled {
+				return nil, nil
+			}
+			vipBundle.InboundFlightCanceled = true
+		case vipBundle.ReturnFlightID:
+			if vipBundle.ReturnFlightCanceled {
+				return nil, nil
+			}
+			vipBundle.ReturnFlightCanceled = true
+		default:
+			return nil, nil
+		}
+
+		return p.next(ctx, vipBundle)
+	})
+}
+
+func (p VipBundleProcessManager) OnBookingCanceled(ctx context.Context, event *entities.BookingCanceled) error {
+	return p.update(ctx, event.BookingID, func(ctx context.Context, vipBundle *entities.VipBundle) ([]entities.DelayedMessage, error) {
+		if vipBundle.BookingCanceled {
+			return nil, nil
+		}
+
+		vipBundle.BookingCanceled = true
+
+		return p.next(ctx, vipBundle)
+	})
+}
+
+// OnReservationExpired compensates the bundle whose booking expired. The expired booking is removed already,
+// so it's not canceled again.
+func (p VipBundleProcessManager) OnReservationExpired(ctx context.Context, event *entities.ReservationExpired) error {
+	return p.update(ctx, event.BookingID, func(ctx context.Context, vipBundle *entities.VipBundle) ([]entities.DelayedMessage, error) {
+		if vipBundle.BookingCanceled {
+			return nil, nil
+		}
+
+		vipBundle.BookingCanceled = true
+
+		if vipBundle.Status == entities.VipBundleStatusInProgress {
+			log.FromContext(ctx).Infof("VIP bundle %s booking expired, compensating", vipBundle.VipBundleID)
+
+			vipBundle.Status = entities.VipBundleStatusCompensating
+			vipBundle.FailureReason = "booking expired"
+		}
+
+		return p.next(ctx, vipBundle)
+	})
+}
+
+func (p VipBundleProcessManager) fail(ctx context.Context, bookingID string, failureReason string) error {
+	return p.update(ctx, bookingID, func(ctx context.Context, vipBundle *entities.VipBundle) ([]entities.DelayedMessage, error) {
+		if vipBundle.Status != entities.VipBundleStatusInProgress {
+			return nil, nil
+		}
+
+		log.FromContext(ctx).Infof("VIP bundle %s failed, compensating: %s", vipBundle.VipBundleID, failureReason)
+
+		vipBundle.Status = entities.VipBundleStatusCompensating
+		vipBundle.FailureReason = failureReason
+
+		return p.next(ctx, vipBundle)
+	})
+}
+
+// update runs updateFn with the bundle of the booking, bookings made outside of bundles are ignored.
+func (p VipBundleProcessManager) update(
+	ctx context.Context,
+	bookingID string,
+	updateFn func(ctx context.Context, vipBundle *entities.VipBundle) ([]entities.DelayedMessage, error),
+) error {
+	err := p.repository.UpdateByBookingID(ctx, bookingID, updateFn)
+	if errors.Is(err, entities.ErrVipBundleNotFound) {
+		return nil
+	}
+
+	return err
+}
+
+// next returns the command of the next step (or compensation), or finalizes the bundle when there is nothing left to do.
+func (p VipBundleProcessManager) next(ctx context.Context, vipBundle *entities.VipBundle) ([]entities.DelayedMessage, error) {
+	switch vipBundle.Status {
+	case entities.VipBundleStatusInProgress:
+		return p.nextStep(ctx, vipBundle)
+	case entities.VipBundleStatusCompensating:
+		return p.nextCompensation(ctx, vipBundle)
+	default:
+		return nil, nil
+	}
+}
+
+func (p VipBundleProcessManager) nextStep(ctx context.Context, vipBundle *entities.VipBundle) ([]entities.DelayedMessage, error) {
+	switch {
+	case !vipBundle.BookingMade:
+		// BookShowTickets is sent when the bundle is initialized
+		return nil, nil
+	case vipBundle.InboundFlightTicketIDs == nil:
+		return p.send(ctx, p.bookFlight(vipBundle, vipBundle.InboundFlightID))
+	case vipBundle.ReturnFlightTicketIDs == nil:
+		return p.send(ctx, p.bookFlight(vipBundle, vipBundle.ReturnFlightID))
+	case vipBundle.TaxiBookingID == "":
+		return p.send(ctx, entities.BookTaxi{
+			Header:             entities.NewEventHeader(),
+			BookingID:          vipBundle.BookingID,
+			CustomerEmail:      vipBundle.CustomerEmail,
+			NumberOfPassengers: len(vipBundle.Passengers),
+		})
+	default:
+		vipBundle.Status = entities.VipBundleStatusFinalized
+		return p.finalize(ctx, vipBundle, true)
+	}
+}
+
+func (p VipBundleProcessManager) nextCompensation(ctx context.Context, vipBundle *entities.VipBundle) ([]entities.DelayedMessage, error) {
+	switch {
+	case len(vipBundle.ReturnFlightTicketIDs) > 0 && !vipBundle.ReturnFlightCanceled:
+		return p.send(ctx, p.cancelFlightTickets(vipBundle, vipBundle.ReturnFlightID, vipBundle.ReturnFlightTicketIDs))
+	case len(vipBundle.InboundFlightTicketIDs) > 0 && !vipBundle.InboundFlightCanceled:
+		return p.send(ctx, p.cancelFlightTickets(vipBundle, vipBundle.InboundFlightID, vipBundle.InboundFlightTicketIDs))
+	case vipBundle.BookingMade && !vipBundle.BookingCanceled:
+		return p.send(ctx, entities.CancelBooking{
+			Header:    entities.NewEventHeader(),
+			BookingID: vipBundle.BookingID,
+			ShowID:    vipBundle.ShowID,
+		})
+	default:
+		vipBundle.Status = entities.VipBundleStatusFailed
+		return p.finalize(ctx, vipBundle, false)
+	}
+}
+
+func (p VipBundleProcessManager) bookFlight(vipBundle *entities.VipBundle, flightID string) entities.BookFlight {
+	return entities.BookFlight{
+		Header:         entities.NewEventHeader(),
+		BookingID:      vipBundle.BookingID,
+		FlightID:       flightID,
+		CustomerEmail:  vipBundle.CustomerEmail,
+		PassengerNames: vipBundle.Passengers,
+	}
+}
+
+func (p VipBundleProcessManager) cancelFlightTickets(vipBundle *entities.VipBundle, flightID string, ticketIDs []string) entities.CancelFlightTickets {
+	return entities.CancelFlightTickets{
+		Header:          entities.NewEventHeader(),
+		BookingID:       vipBundle.BookingID,
+		FlightID:        flightID,
+		FlightTicketIDs: ticketIDs,
+	}
+}
+
+func (p VipBundleProcessManager) finalize(ctx context.Context, vipBundle *entities.VipBundle, success bool) ([]entities.DelayedMessage, error) {
+	log.FromContext(ctx).Infof("VIP bundle %s finalized with status %s", vipBundle.VipBundleID, vipBundle.Status)
+
+	return outbox(ctx, p.eventOutbox, entities.VipBundleFinalized{
+		Header:      entities.NewEventHeader(),
+		VipBundleID: vipBundle.VipBundleID,
+		Success:     success,
+	})
+}
+
+func (p VipBundleProcessManager) send(ctx context.Context, command any) ([]entities.DelayedMessage, error) {
+	return outbox(ctx, p.commandOutbox, command)
+}
+
+func outbox(ctx context.Context, outbox Outbox, message any) ([]entities.DelayedMessage, error) {
+	msg, err := outbox.OutboxMessage(ctx, message)
+	if err != nil {
+		return nil, err
+	}
+
+	return []entities.DelayedMessage{msg}, nil
+}
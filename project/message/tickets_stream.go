@@ -0,0 +1,246 @@
+package message
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"tickets/entities"
+
+	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+// ticketsStreamBuffer is how many changes a subscriber may fall behind before it's disconnected.
+const ticketsStreamBuffer = 64
+
+var ticketStatusesByEvent = map[string]string{
+	cqrs.StructName(entities.TicketBookingConfirmed{}): entities.TicketStatusConfirmed,
+	cqrs.StructName(entities.TicketBookingCanceled{}):  entities.TicketStatusCanceled,
+	cqrs.StructName(entities.TicketReceiptIssued{}):    entities.TicketStatusReceipted,
+	cqrs.StructName(entities.TicketRefundCalculated{}): entities.TicketStatusRefunded,
+}
+
+type TicketsStreamEventsStore interface {
+	EventExists(ctx context.Context, eventID string) (bool, error)
+	EventsAfter(ctx context.Context, eventID string) ([]entities.StoredEvent, error)
+}
+
+type TicketsStreamBookingsRepository interface {
+	BookingByID(ctx context.Context, bookingID string) (entities.Booking, error)
+}
+
+// TicketsStream pushes ticket status changes to subscribers of this instance, so every instance consumes
+// all events with a consumer group of its own.
+// Subscribers that can't keep up are disconnected, so they can resume from the last change they received.
+type TicketsStream struct {
+	eventsStore        TicketsStreamEventsStore
+	bookingsRepository TicketsStreamBookingsRepository
+
+	lock        *sync.Mutex
+	subscribers map[chan entities.TicketStatusChanged]struct{}
+}
+
+func NewTicketsStream(
+	eventsStore TicketsStreamEventsStore,
+	bookingsRepository TicketsStreamBookingsRepository,
+) *TicketsStream {
+	if eventsStore == nil {
+		panic("eventsStore is nil")
+	}
+	if bookingsRepository == nil {
+		panic("bookingsRepository is nil")
+	}
+
+	return &TicketsStream{
+		eventsStore:        eventsStore,
+		bookingsRepository: bookingsRepository,
+		lock:               &sync.Mutex{},
+		subscribers:        map[chan entities.TicketStatusChanged]struct{}{},
+	}
+}
+
+// Subscribe streams changes until ctx is done. When lastEventID is set, the changes stored after it come first.
+func (s *TicketsStream) Subscribe(ctx context.Context, lastEventID string) (<-chan entities.TicketStatusChanged, error) {
+	// subscribing before reading the backlog, so no change is missed in between
+	live := make(chan entities.TicketStatusChanged, ticketsStreamBuffer)
+	s.lock.Lock()
+	s.subscribers[live] = struct{}{}
+	s.lock.Unlock()
+
+	backlog, err := s.backlog(ctx, lastEventID)
+	if err != nil {
+		s.unsubscribe(live)
+		return nil, err
+	}
+
+	out := make(chan entities.TicketStatusChanged)
+	go func() {
+		defer close(out)
+		defer s.unsubscribe(live)
+
+		sent := make(map[string]struct{}, len(backlog))
+		for _, change := range backlog {
+			select {
+			case out <- change:
+				sent[change.EventID] = struct{}{}
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		for {
+			select {
+			case change, ok := <-live:
+				if !ok {
+					return
+				}
+				if _, ok := sent[change.EventID]; ok {
+					continue
+				}
+
+				select {
+				case out <- change:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+func (s *TicketsStream) backlog(ctx context.Context, lastEventID string) ([]entities.TicketStatusChanged, error) {
+	if lastEventID == "" {
+		return nil, nil
+	}
+
+	events, err := s.eventsStore.EventsAfter(ctx, lastEventID)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []entities.TicketStatusChanged
+	for _, event := range events {
+		change, ok, err := s.statusChange(ctx, event.EventName, event.Payload)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			changes = append(changes, change)
+		}
+	}
+
+	return changes, nil
+}
+
+func (s *TicketsStream) unsubscribe(subscriber chan entities.TicketStatusChanged) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, ok := s.subscribers[subscriber]; ok {
+		delete(s.subscribers, subscriber)
+		close(subscriber)
+	}
+}
+
+func (s *TicketsStream) handle(msg *message.Message) error {
+	event, err := storedEventFromMessage(msg)
+	if err != nil {
+		return err
+	}
+
+	change, ok, err := s.statusChange(msg.Context(), event.EventName, event.Payload)
+	if err != nil || !ok {
+		return err
+	}
+
+	// the event store is fed by another consumer group, which may be behind;
+	// waiting for the event to be stored before it's pushed makes sure a subscriber can resume after it
+	stored, err := s.eventsStore.EventExists(msg.Context(), event.EventID)
+	if err != nil {
+		return err
+	}
+	if !stored {
+		return fmt.Errorf("event %s is not stored yet", event.EventID)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for subscriber := range s.subscribers {
+		select {
+		case subscriber <- change:
+		default:
+			log.FromContext(msg.Context()).Warn("Tickets stream subscriber is too slow, disconnecting")
+			delete(s.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+
+	return nil
+}
+
+func (s *TicketsStream) statusChange(
+	ctx context.Context,
+	eventName string,
+	payload []byte,
+) (entities.TicketStatusChanged, bool, error) {
+	status, ok := ticketStatusesByEvent[eventName]
+	if !ok {
+		return entities.TicketStatusChanged{}, false, nil
+	}
+
+	// all ticket events share these fields
+	var event struct {
+		Header        entities.EventHeader `json:"header"`
+		TicketID      string               `json:"ticket_id"`
+		BookingID     string               `json:"booking_id"`
+		CustomerEmail string               `json:"customer_email"`
+		// only in TicketRefundCalculated
+		Refund entities.Money `json:"refund"`
+	}
+	err := json.Unmarshal(payload, &event)
+	if err != nil {
+		return entities.TicketStatusChanged{}, false, fmt.Errorf("failed to unmarshal %s: %w", eventName, err)
+	}
+
+	// tickets the refund policy refunds nothing for stay canceled
+	if status == entities.TicketStatusRefunded && !event.Refund.Positive() {
+		return entities.TicketStatusChanged{}, false, nil
+	}
+
+	changedAt, err := event.Header.PublishedAtTime()
+	if err != nil {
+		return entities.TicketStatusChanged{}, false, fmt.Errorf("failed to parse published_at: %w", err)
+	}
+
+	change := entities.TicketStatusChanged{
+		EventID:       event.Header.ID,
+		TicketID:      event.TicketID,
+		BookingID:     event.BookingID,
+		CustomerEmail: event.CustomerEmail,
+		Status:        status,
+		ChangedAt:     changedAt,
+	}
+
+	if event.BookingID != "" {
+		booking, err := s.bookingsRepository.BookingByID(ctx, event.BookingID)
+		if err != nil && !errors.Is(err, entities.ErrBookingNotFound) {
+			return entities.TicketStatusChanged{}, false, fmt.Errorf("failed to get booking %s: %w", event.BookingID, err)
+		}
+		if err == nil {
+			change.ShowID = booking.ShowID
+			if change.CustomerEmail == "" {
+				change.CustomerEmail = booking.CustomerEmail
+			}
+		}
+	}
+
+	return change, true, nil
+}
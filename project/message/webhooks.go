@@ -0,0 +1,107 @@
+package message
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"tickets/entities"
+
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+type WebhooksRepository interface {
+	Enabled(ctx context.Context) ([]entities.Webhook, error)
+}
+
+type WebhooksBookingsRepository interface {
+	BookingByID(ctx context.Context, bookingID string) (entities.Booking, error)
+}
+
+// fanOutWebhooks sends a DeliverWebhook command per endpoint subscribed to the event,
+// so a failing endpoint is retried without resending deliveries to the others.
+// Partners get only events of tickets booked for their own shows.
+func fanOutWebhooks(
+	webhooksRepository WebhooksRepository,
+	bookingsRepository WebhooksBookingsRepository,
+	commandBus *cqrs.CommandBus,
+) message.NoPublishHandlerFunc {
+	return func(msg *message.Message) error {
+		event, err := storedEventFromMessage(msg)
+		if err != nil {
+			return err
+		}
+
+		webhooks, err := webhooksRepository.Enabled(msg.Context())
+		if err != nil {
+			return err
+		}
+
+		var subscribed []entities.Webhook
+		for _, webhook := range webhooks {
+			if webhook.SubscribedTo(event.EventName) {
+				subscribed = append(subscribed, webhook)
+			}
+		}
+		if len(subscribed) == 0 {
+			return nil
+		}
+
+		showID, err := eventShowID(msg.Context(), bookingsRepository, event)
+		if err != nil {
+			return err
+		}
+		if showID == "" {
+			return nil
+		}
+
+		for _, webhook := range subscribed {
+			if !webhook.CoversShow(showID) {
+				continue
+			}
+
+			err = commandBus.Send(msg.Context(), entities.DeliverWebhook{
+				Header:    entities.NewEventHeader(),
+				WebhookID: webhook.WebhookID,
+				EventID:   event.EventID,
+				EventName: event.EventName,
+				Payload:   event.Payload,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to send DeliverWebhook command: %w", err)
+			}
+		}
+
+		return nil
+	}
+}
+
+// eventShowID returns the show of the event's booking, or an empty string when it isn't known.
+func eventShowID(
+	ctx context.Context,
+	bookingsRepository WebhooksBookingsRepository,
+	event entities.StoredEvent,
+) (string, error) {
+	// all events partners can subscribe to have the booking of the ticket
+	var payload struct {
+		BookingID string `json:"booking_id"`
+	}
+	err := json.Unmarshal(event.Payload, &payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to unmarshal %s: %w", event.EventName, err)
+	}
+	if payload.BookingID == "" {
+		return "", nil
+	}
+
+	booking, err := bookingsRepository.BookingByID(ctx, payload.BookingID)
+	if errors.Is(err, entities.ErrBookingNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get booking %s: %w", payload.BookingID, err)
+	}
+
+	return booking.ShowID, nil
+}
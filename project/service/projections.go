@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"tickets/db"
+	"tickets/message"
+	"tickets/message/projection"
+
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+	"github.com/jmoiron/sqlx"
+)
+
+// newProjections returns the event handlers that only update read models,
+// so they can be rebuilt by replaying the event store.
+func newProjections(dbConn *sqlx.DB) []message.Projection {
+	opsBookings := projection.NewOpsBookings(db.NewOpsBookingsRepository(dbConn))
+
+	return []message.Projection{
+		{
+			Name: "OpsBookings",
+			Handlers: []cqrs.EventHandler{
+				cqrs.NewEventHandler("OpsBookings.OnBookingMade", opsBookings.OnBookingMade),
+				cqrs.NewEventHandler("OpsBookings.OnTicketBookingConfirmed", opsBookings.OnTicketBookingConfirmed),
+				cqrs.NewEventHandler("OpsBookings.OnTicketBookingCanceled", opsBookings.OnTicketBookingCanceled),
+				cqrs.NewEventHandler("OpsBookings.OnTicketRefundCalculated", opsBookings.OnTicketRefundCalculated),
+				cqrs.NewEventHandler("OpsBookings.OnTicketPrinted", opsBookings.OnTicketPrinted),
+				cqrs.NewEventHandler("OpsBookings.OnTicketReceiptIssued", opsBookings.OnTicketReceiptIssued),
+			},
+		},
+	}
+}
+
+// Replay rebuilds a single projection from the event store without starting the service.
+func Replay(ctx context.Context, dbConn *sqlx.DB, projectionName string) (int, error) {
+	err := db.InitializeDatabaseSchema(ctx, dbConn)
+	if err != nil {
+		return 0, err
+	}
+
+	replayer := message.NewEventReplayer(db.NewEventsRepository(dbConn), newProjections(dbConn))
+
+	return replayer.Replay(ctx, projectionName)
+}
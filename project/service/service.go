@@ -2,13 +2,22 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	stdHTTP "net/http"
+	"tickets/config"
+	"tickets/db"
 	ticketsHttp "tickets/http"
 	"tickets/message"
+	"tickets/message/command"
 	"tickets/message/event"
+	"tickets/message/saga"
+	"time"
 
 	"github.com/ThreeDotsLabs/go-event-driven/common/log"
+	"github.com/ThreeDotsLabs/watermill"
 	watermillMessage "github.com/ThreeDotsLabs/watermill/message"
+	"github.com/jmoiron/sqlx"
 	"github.com/labstack/echo/v4"
 	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
@@ -20,55 +29,224 @@ func init() {
 }
 
 type Service struct {
-	watermillRouter *watermillMessage.Router
-	echoRouter      *echo.Echo
+	db                  *sqlx.DB
+	watermillRouter     *watermillMessage.Router
+	echoRouter          *echo.Echo
+	delayedRelay        event.DelayedRelay
+	reservationsExpirer event.ReservationsExpirer
+	redisPublisher      watermillMessage.Publisher
+	redisClient         *redis.Client
+	// ticketsStreamConsumerGroup is removed at shutdown, as it's of this instance only
+	ticketsStreamConsumerGroup string
+	cfg                        config.Config
 }
 
 func New(
+	cfg config.Config,
+	dbConn *sqlx.DB,
 	redisClient *redis.Client,
 	spreadsheetsService event.SpreadsheetsService,
 	receiptsService event.ReceiptsService,
+	deadNationService event.DeadNationService,
+	filesService event.FilesService,
+	notificationsService event.NotificationsService,
+	transportationService command.TransportationService,
+	paymentsService command.PaymentsService,
+	webhookSender command.WebhookSender,
+	gatewayReadinessCheck ticketsHttp.ReadinessCheck,
+	now func() time.Time,
 ) Service {
 	watermillLogger := log.NewWatermill(log.FromContext(context.Background()))
 
-	var redisPublisher watermillMessage.Publisher
-	redisPublisher = message.NewRedisPublisher(redisClient, watermillLogger)
-	redisPublisher = log.CorrelationPublisherDecorator{Publisher: redisPublisher}
+	redisPublisher := message.NewRedisPublisher(redisClient, watermillLogger)
 
-	eventBus := event.NewEventBus(redisPublisher)
+	showsRepository := db.NewShowsRepository(dbConn)
+	bookingsRepository := db.NewBookingsRepository(dbConn)
+	vipBundlesRepository := db.NewVipBundlesRepository(dbConn)
+	eventsRepository := db.NewEventsRepository(dbConn)
+	opsBookingsRepository := db.NewOpsBookingsRepository(dbConn)
+	webhooksRepository := db.NewWebhooksRepository(dbConn)
+	delayedMessagesRepository := db.NewDelayedMessagesRepository(dbConn)
+	eventBus := event.NewEventBus(log.CorrelationPublisherDecorator{Publisher: redisPublisher}, delayedMessagesRepository)
+	commandBus := command.NewCommandBus(log.CorrelationPublisherDecorator{Publisher: redisPublisher}, delayedMessagesRepository)
+	waitlistRepository := db.NewWaitlistRepository(dbConn)
+	promoCodesRepository := db.NewPromoCodesRepository(dbConn)
 
-	eventsHandler := event.NewHandler(spreadsheetsService, receiptsService)
+	projections := newProjections(dbConn)
 
-	eventProcessorConfig := event.NewProcessorConfig(redisClient, watermillLogger)
+	ticketsStream := message.NewTicketsStream(eventsRepository, bookingsRepository)
+	// every instance pushes all changes to its own clients
+	ticketsStreamConsumerGroup := cfg.Messages.ConsumerGroupPrefix + "TicketsStream." + watermill.NewShortUUID()
+
+	eventsHandler := event.NewHandler(
+		eventBus,
+		commandBus.CommandBus,
+		spreadsheetsService,
+		receiptsService,
+		deadNationService,
+		filesService,
+		notificationsService,
+		showsRepository,
+		bookingsRepository,
+		db.NewShowRemindersRepository(dbConn),
+		waitlistRepository,
+		cfg.Sheets,
+		cfg.Notifications,
+		cfg.Waitlist,
+		now,
+	)
+
+	eventProcessorConfig := event.NewProcessorConfig(
+		redisClient,
+		cfg.Messages.ConsumerGroupPrefix,
+		watermillLogger,
+	)
+
+	commandsHandler := command.NewHandler(
+		eventBus.EventBus,
+		eventBus,
+		commandBus,
+		transportationService,
+		paymentsService,
+		bookingsRepository,
+		webhooksRepository,
+		webhookSender,
+		cfg.Webhooks,
+		cfg.Reservations,
+		now,
+	)
+
+	vipBundleProcessManager := saga.NewVipBundleProcessManager(
+		commandBus.CommandBus,
+		commandBus,
+		eventBus,
+		vipBundlesRepository,
+	)
+
+	commandProcessorConfig := command.NewProcessorConfig(
+		redisClient,
+		cfg.Messages.ConsumerGroupPrefix,
+		watermillLogger,
+	)
 
 	watermillRouter := message.NewWatermillRouter(
 		eventsHandler,
 		eventProcessorConfig,
+		commandsHandler,
+		commandProcessorConfig,
+		vipBundleProcessManager,
+		projections,
+		eventsRepository,
+		message.NewRedisSubscriber(redisClient, cfg.Messages.ConsumerGroupPrefix+"StoreEvent", watermillLogger),
+		ticketsStream,
+		message.NewRedisSubscriberFromLatest(redisClient, ticketsStreamConsumerGroup, watermillLogger),
+		webhooksRepository,
+		bookingsRepository,
+		commandBus.CommandBus,
+		message.NewRedisSubscriber(redisClient, cfg.Messages.ConsumerGroupPrefix+"FanOutWebhooks", watermillLogger),
+		// events published before forwarding are already in the topics named after them
+		message.NewRedisSubscriberFromLatest(redisClient, cfg.Messages.ConsumerGroupPrefix+"ForwardEvents", watermillLogger),
+		redisPublisher,
+		cfg.Messages.Retry,
+		cfg.Shutdown.HandlersDrainTimeout,
 		watermillLogger,
 	)
 
-	echoRouter := ticketsHttp.NewHttpRouter(eventBus)
+	readinessChecks := map[string]ticketsHttp.ReadinessCheck{
+		"postgres": func(ctx context.Context) error {
+			return dbConn.PingContext(ctx)
+		},
+		"redis": func(ctx context.Context) error {
+			return redisClient.Ping(ctx).Err()
+		},
+		"watermill_router": func(ctx context.Context) error {
+			select {
+			case <-watermillRouter.Running():
+			default:
+				return errors.New("router is not running yet")
+			}
+
+			if watermillRouter.IsClosed() {
+				return errors.New("router is closed")
+			}
+
+			return nil
+		},
+	}
+	if gatewayReadinessCheck != nil {
+		readinessChecks["gateway"] = gatewayReadinessCheck
+	}
+
+	echoRouter := ticketsHttp.NewHttpRouter(
+		eventBus.EventBus,
+		eventBus,
+		showsRepository,
+		bookingsRepository,
+		vipBundlesRepository,
+		eventsRepository,
+		message.NewEventReplayer(eventsRepository, projections),
+		opsBookingsRepository,
+		ticketsStream,
+		webhooksRepository,
+		waitlistRepository,
+		promoCodesRepository,
+		readinessChecks,
+		now,
+		cfg.Reservations.TTL,
+		cfg.Webhooks,
+	)
+
+	delayedRelay := event.NewDelayedRelay(
+		delayedMessagesRepository,
+		log.CorrelationPublisherDecorator{Publisher: redisPublisher},
+		cfg.Messages.Delayed,
+	)
 
 	return Service{
+		dbConn,
 		watermillRouter,
 		echoRouter,
+		delayedRelay,
+		event.NewReservationsExpirer(bookingsRepository, eventBus, now, cfg.Reservations),
+		redisPublisher,
+		redisClient,
+		ticketsStreamConsumerGroup,
+		cfg,
 	}
 }
 
 func (s Service) Run(
 	ctx context.Context,
 ) error {
+	err := db.InitializeDatabaseSchema(ctx, s.db)
+	if err != nil {
+		return err
+	}
+
 	errgrp, ctx := errgroup.WithContext(ctx)
 
 	errgrp.Go(func() error {
-		return s.watermillRouter.Run(ctx)
+		// the router is closed explicitly in shutdown, so in-flight messages are not cut off by ctx cancellation
+		return s.watermillRouter.Run(context.Background())
+	})
+
+	errgrp.Go(func() error {
+		return s.delayedRelay.Run(ctx)
+	})
+
+	errgrp.Go(func() error {
+		return s.reservationsExpirer.Run(ctx)
 	})
 
 	errgrp.Go(func() error {
 		// we don't want to start HTTP server before Watermill router (so service won't be healthy before it's ready)
-		<-s.watermillRouter.Running()
+		select {
+		case <-s.watermillRouter.Running():
+		case <-ctx.Done():
+			return nil
+		}
 
-		err := s.echoRouter.Start(":8080")
+		err := s.echoRouter.Start(fmt.Sprintf(":%d", s.cfg.HTTP.Port))
 
 		if err != nil && err != stdHTTP.ErrServerClosed {
 			return err
@@ -79,8 +257,62 @@ func (s Service) Run(
 
 	errgrp.Go(func() error {
 		<-ctx.Done()
-		return s.echoRouter.Shutdown(context.Background())
+		return s.shutdown()
 	})
 
 	return errgrp.Wait()
 }
+
+// shutdown stops accepting HTTP requests, then stops consuming messages and waits for in-flight handlers,
+// and finally closes the Redis publisher and client, as handlers may still publish until they are done.
+func (s Service) shutdown() error {
+	logger := log.FromContext(context.Background())
+
+	var errs []error
+
+	logger.Info("Shutting down HTTP server")
+
+	httpCtx, cancel := context.WithTimeout(context.Background(), s.cfg.Shutdown.HTTPServerTimeout)
+	defer cancel()
+
+	if err := s.echoRouter.Shutdown(httpCtx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to shut down HTTP server: %w", err))
+	}
+
+	logger.Info("Draining in-flight messages")
+
+	if err := s.watermillRouter.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to close Watermill router: %w", err))
+	}
+
+	// Redis subscribers and the publisher close the shared client themselves, so it may be closed already
+	if err := s.redisPublisher.Close(); err != nil && !errors.Is(err, redis.ErrClosed) {
+		errs = append(errs, fmt.Errorf("failed to close Redis publisher: %w", err))
+	}
+
+	// the subscriber closed the shared client, so the group is removed with a client of its own
+	if err := s.removeTicketsStreamConsumerGroup(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := s.redisClient.Close(); err != nil && !errors.Is(err, redis.ErrClosed) {
+		errs = append(errs, fmt.Errorf("failed to close Redis client: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s Service) removeTicketsStreamConsumerGroup() error {
+	client := redis.NewClient(s.redisClient.Options())
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.Shutdown.HTTPServerTimeout)
+	defer cancel()
+
+	err := client.XGroupDestroy(ctx, event.AllEventsTopic, s.ticketsStreamConsumerGroup).Err()
+	if err != nil {
+		return fmt.Errorf("failed to remove consumer group %s: %w", s.ticketsStreamConsumerGroup, err)
+	}
+
+	return nil
+}
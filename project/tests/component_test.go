@@ -1,47 +1,1741 @@
 package tests_test
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"github.com/google/uuid"
 	"github.com/lithammer/shortuuid/v3"
+	"io"
+	"maps"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"tickets/api"
+	"tickets/config"
+	"tickets/db"
 	"tickets/entities"
 	"tickets/message"
+	"tickets/message/event"
 	"tickets/service"
 	"time"
 
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill-redisstream/pkg/redisstream"
+	watermillMessage "github.com/ThreeDotsLabs/watermill/message"
+	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestComponent(t *testing.T) {
 	// place for your tests!
-	redisClient := message.NewRedisClient(os.Getenv("REDIS_ADDR"))
+	cfg := config.Default()
+	cfg.Redis.Addr = os.Getenv("REDIS_ADDR")
+	cfg.Postgres.URL = os.Getenv("POSTGRES_URL")
+	cfg.Notifications.Emails["TicketBookingConfirmed"]["lt"] = config.Email{
+		Subject: "Bilietas {{.ticket_id}} patvirtintas",
+		Body:    "Jūsų bilietas {{.ticket_id}} patvirtintas.",
+	}
+
+	cfg.Messages.Delayed.PollInterval = 100 * time.Millisecond
+	cfg.Webhooks.MaxAttempts = 2
+	cfg.Webhooks.DisableAfterFailures = 2
+	cfg.Webhooks.RetryInterval = 100 * time.Millisecond
+	cfg.Webhooks.AdminToken = adminToken
+	// the partners' endpoints are test servers on localhost
+	cfg.Webhooks.AllowPrivateAddresses = true
+	cfg.Reservations.CheckInterval = 100 * time.Millisecond
+
+	dbConn, err := db.NewPostgresConnection(cfg.Postgres.URL)
+	require.NoError(t, err)
+	defer dbConn.Close()
+
+	redisClient := message.NewRedisClient(cfg.Redis)
 	defer redisClient.Close()
 
 	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	defer cancel()
+
+	spreadsheetsService := &api.SpreadsheetsMock{}
+	receiptsService := &api.ReceiptsMock{}
+	deadNationService := &api.DeadNationMock{}
+	filesService := &api.FilesMock{}
+	notificationsService := &api.NotificationsMock{}
+	transportationService := &api.TransportationMock{}
+	paymentsService := &api.PaymentsMock{}
+	clock := &testClock{}
+
+	go func() {
+		svc := service.New(
+			cfg,
+			dbConn,
+			redisClient,
+			spreadsheetsService,
+			receiptsService,
+			deadNationService,
+			filesService,
+			notificationsService,
+			transportationService,
+			paymentsService,
+			api.NewWebhookClient(cfg.Webhooks),
+			nil,
+			clock.Now,
+		)
+		assert.NoError(t, svc.Run(ctx))
+	}()
+
+	waitForHttpServer(t)
+	testHealthReady(t)
+	testTicketsStatusConfirmed(t, receiptsService, spreadsheetsService)
+	testTicketsStatusCanceled(t, spreadsheetsService)
+	testShows(t)
+	testBookTickets(t)
+	testBookTicketsInDeadNation(t, deadNationService)
+	testPrintTicket(t, redisClient, filesService)
+	testNotifications(t, notificationsService)
+	testVIPTaxi(t, transportationService)
+	testVipBundle(t, transportationService)
+	testVipBundleCompensated(t, transportationService)
+	testEventStore(t)
+	testReplayUnknownProjection(t)
+	testOpsBookings(t)
+	testTicketsStream(t)
+	testWebhooks(t)
+	testDelayedPublish(t, dbConn, redisClient)
+	testShowReminders(t, notificationsService, cfg.Notifications.ShowReminderBefore)
+	testSeatMap(t, redisClient, spreadsheetsService)
+	testRefundPolicy(t, spreadsheetsService, notificationsService, paymentsService)
+	testPromoCodes(t, receiptsService)
+	// moving the clock expires the unconfirmed bookings of all tests, so it goes last
+	testReservationExpiry(t, clock, dbConn, redisClient, deadNationService, transportationService, cfg.Reservations.TTL)
+	testWaitlist(t, clock, redisClient, notificationsService, cfg.Reservations.TTL, cfg.Waitlist.OfferTTL)
+}
+
+func TestConfigFileOverridesDefaultsPerEntry(t *testing.T) {
+	path := t.TempDir() + "/config.yaml"
+	err := os.WriteFile(path, []byte(`
+sheets:
+  TicketBookingConfirmed:
+    name: printed-tickets
+  TicketBookingCanceled:
+    name: refunds
+notifications:
+  emails:
+    TicketBookingConfirmed:
+      de:
+        subject: Ihr Ticket {{.ticket_id}} ist bestätigt
+        body: Ihr Ticket {{.ticket_id}} ist bestätigt.
+    ShowReminderDue:
+      en:
+        subject: "{{.title}} starts soon"
+`), 0o600)
+	require.NoError(t, err)
+
+	t.Setenv(config.FileEnv, path)
+	t.Setenv("POSTGRES_URL", "postgres://localhost/db")
+	t.Setenv("REDIS_ADDR", "localhost:6379")
+	t.Setenv("GATEWAY_ADDR", "http://localhost:8888")
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	defaults := config.Default()
+
+	assert.Equal(t, config.Sheet{
+		Name:    "printed-tickets",
+		Columns: defaults.Sheets["TicketBookingConfirmed"].Columns,
+	}, cfg.Sheets["TicketBookingConfirmed"])
+	assert.Equal(t, config.Sheet{
+		Name:    "refunds",
+		Columns: defaults.Sheets["TicketRefundCalculated"].Columns,
+	}, cfg.Sheets["TicketRefundCalculated"], "the sheet of the renamed event should be merged into the new one")
+	assert.NotContains(t, cfg.Sheets, "TicketBookingCanceled")
+
+	confirmedEmails := cfg.Notifications.Emails["TicketBookingConfirmed"]
+	assert.Equal(t, defaults.Notifications.Emails["TicketBookingConfirmed"]["en"], confirmedEmails["en"])
+	assert.Equal(t, "Ihr Ticket {{.ticket_id}} ist bestätigt", confirmedEmails["de"].Subject)
+
+	reminder := cfg.Notifications.Emails["ShowReminderDue"]["en"]
+	assert.Equal(t, "{{.title}} starts soon", reminder.Subject)
+	assert.Equal(t, defaults.Notifications.Emails["ShowReminderDue"]["en"].Body, reminder.Body)
+}
+
+func testWaitlist(
+	t *testing.T,
+	clock *testClock,
+	redisClient *redis.Client,
+	notificationsService *api.NotificationsMock,
+	reservationTTL time.Duration,
+	offerTTL time.Duration,
+) {
+	showID := postShow(t, map[string]any{
+		"title":             "Sold Out Show",
+		"venue":             "Test Venue",
+		"start_time":        time.Now().Add(7 * 24 * time.Hour).UTC(),
+		"number_of_tickets": 2,
+	})
+
+	resp := postWaitlist(t, showID, "early@example.com")
+	assert.Equal(t, http.StatusConflict, resp.StatusCode, "tickets can still be booked")
+
+	bookTicketsForBookingID(t, showID, 2)
+
+	resp = bookTickets(t, showID, 1)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "show should be sold out")
+
+	resp = postWaitlist(t, uuid.NewString(), "late@example.com")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	bookingMade := subscribeToEvents(t, redisClient, "BookingMade")
+
+	first := "first-" + shortuuid.New() + "@example.com"
+	second := "second-" + shortuuid.New() + "@example.com"
+	entryIDs := map[string]string{}
+	for _, customerEmail := range []string{first, second} {
+		resp = postWaitlist(t, showID, customerEmail)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var body struct {
+			EntryID string `json:"entry_id"`
+		}
+		err := json.NewDecoder(resp.Body).Decode(&body)
+		require.NoError(t, err)
+		entryIDs[customerEmail] = body.EntryID
+	}
+
+	resp = claimWaitlistOffer(t, entryIDs[first])
+	assert.Equal(t, http.StatusConflict, resp.StatusCode, "nothing was offered yet")
+
+	resp = claimWaitlistOffer(t, uuid.NewString())
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	assertOfferSent := func(customerEmail string) {
+		t.Helper()
+		assert.EventuallyWithT(
+			t,
+			func(collectT *assert.CollectT) {
+				assert.True(collectT, slices.ContainsFunc(notificationsService.SentEmails(), func(email entities.Email) bool {
+					return email.To == customerEmail && email.Subject == "Tickets are waiting for you"
+				}), "offer not sent to %s", customerEmail)
+			},
+			10*time.Second,
+			100*time.Millisecond,
+		)
+	}
+
+	// the unpaid booking expires, its tickets are offered to the first customer in line
+	clock.Advance(reservationTTL + time.Second)
+	assertOfferSent(first)
+
+	// the unclaimed offer passes to the next customer
+	clock.Advance(offerTTL + time.Second)
+	assertOfferSent(second)
+
+	resp = claimWaitlistOffer(t, entryIDs[first])
+	assert.Equal(t, http.StatusGone, resp.StatusCode)
+
+	// the offered tickets are booked for real once claimed, claiming again is a no-op
+	for range 2 {
+		resp = claimWaitlistOffer(t, entryIDs[second])
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	assertEventPublished(t, bookingMade, func(payload map[string]any) bool {
+		return payload["booking_id"] == entryIDs[second]
+	})
+
+	// tickets canceled one by one are released from their booking and offered too
+	third := "third-" + shortuuid.New() + "@example.com"
+	resp = postWaitlist(t, showID, third)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	tickets := []entities.Ticket{getTestTicket("confirmed"), getTestTicket("confirmed")}
+	for i := range tickets {
+		tickets[i].BookingID = entryIDs[second]
+	}
+	sendTicketsStatus(t, entities.TicketsStatusRequest{Tickets: tickets})
+
+	for i := range tickets {
+		tickets[i].Status = "canceled"
+	}
+	sendTicketsStatus(t, entities.TicketsStatusRequest{Tickets: tickets})
+	assertOfferSent(third)
+}
+
+func claimWaitlistOffer(t *testing.T, entryID string) *http.Response {
+	t.Helper()
+
+	resp, err := http.Post("http://localhost:8080/waitlist/"+entryID+"/claim", "application/json", nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+
+	return resp
+}
+
+func postWaitlist(t *testing.T, showID string, customerEmail string) *http.Response {
+	t.Helper()
+
+	payload, err := json.Marshal(map[string]any{
+		"customer_email":    customerEmail,
+		"number_of_tickets": 2,
+	})
+	require.NoError(t, err)
+
+	resp, err := http.Post("http://localhost:8080/shows/"+showID+"/waitlist", "application/json", bytes.NewBuffer(payload))
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+
+	return resp
+}
+
+func testSeatMap(t *testing.T, redisClient *redis.Client, spreadsheetsService *api.SpreadsheetsMock) {
+	ticketBookingConfirmed := subscribeToEvents(t, redisClient, "TicketBookingConfirmed")
+
+	showID := postShow(t, map[string]any{
+		"title":      "Seated Show",
+		"venue":      "Test Venue",
+		"start_time": time.Now().Add(7 * 24 * time.Hour).UTC(),
+		"seat_map": entities.SeatMap{Sections: []entities.SeatSection{{
+			Name: "Balcony",
+			Rows: []entities.SeatRow{{Name: "A", Seats: 2}, {Name: "B", Seats: 2}},
+		}}},
+	})
+
+	bookSeats := func(seats ...entities.Seat) *http.Response {
+		return sendBookTicketsRequest(t, map[string]any{
+			"show_id":        showID,
+			"customer_email": "seated@example.com",
+			"seats":          seats,
+		})
+	}
+
+	resp := bookSeats(entities.Seat{Section: "Balcony", Row: "C", Number: 1})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "seat outside of the seat map")
+
+	// simultaneous bookings of the same seat, only one of them gets it
+	payload, err := json.Marshal(map[string]any{
+		"show_id":        showID,
+		"customer_email": "seated@example.com",
+		"seats":          []entities.Seat{{Section: "Balcony", Row: "A", Number: 1}},
+	})
+	require.NoError(t, err)
+
+	statusCodes := make([]int, 2)
+	var wg sync.WaitGroup
+	for i := range statusCodes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := http.Post("http://localhost:8080/book-tickets", "application/json", bytes.NewBuffer(payload))
+			if err != nil {
+				return
+			}
+			defer resp.Body.Close()
+			statusCodes[i] = resp.StatusCode
+		}()
+	}
+	wg.Wait()
+	var booked int
+	for _, statusCode := range statusCodes {
+		if statusCode == http.StatusCreated {
+			booked++
+		}
+	}
+	assert.Equal(t, 1, booked, "the seat was booked by %d bookings: %v", booked, statusCodes)
+
+	resp = bookSeats(entities.Seat{Section: "Balcony", Row: "B", Number: 1}, entities.Seat{Section: "Balcony", Row: "B", Number: 2})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var body struct {
+		BookingID string `json:"booking_id"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	require.NoError(t, err)
+
+	first := getTestTicket("confirmed")
+	first.BookingID = body.BookingID
+	second := getTestTicket("confirmed")
+	second.BookingID = body.BookingID
+	sendTicketsStatus(t, entities.TicketsStatusRequest{Tickets: []entities.Ticket{first, second}})
+
+	assertEventPublished(t, ticketBookingConfirmed, func(payload map[string]any) bool {
+		seat, ok := payload["seat"].(map[string]any)
+		return payload["ticket_id"] == first.TicketID && ok && seat["section"] == "Balcony" && seat["row"] == "B"
+	})
+
+	// each ticket of the booking gets one of its seats, in the sheet's last column
+	assert.EventuallyWithT(
+		t,
+		func(collectT *assert.CollectT) {
+			seats := map[string]string{}
+			for _, row := range spreadsheetsService.Rows("tickets-to-print") {
+				if row[0] == first.TicketID || row[0] == second.TicketID {
+					seats[row[0]] = row[len(row)-1]
+				}
+			}
+			assert.ElementsMatch(collectT, []string{"Balcony/B/1", "Balcony/B/2"}, slices.Collect(maps.Values(seats)))
+		},
+		10*time.Second,
+		100*time.Millisecond,
+	)
+}
+
+// testClock runs with the real time, but can be moved forward.
+type testClock struct {
+	mu     sync.Mutex
+	offset time.Duration
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return time.Now().Add(c.offset)
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.offset += d
+}
+
+func testReservationExpiry(
+	t *testing.T,
+	clock *testClock,
+	dbConn *sqlx.DB,
+	redisClient *redis.Client,
+	deadNationService *api.DeadNationMock,
+	transportationService *api.TransportationMock,
+	ttl time.Duration,
+) {
+	bookingsRepository := db.NewBookingsRepository(dbConn)
+	reservationExpired := subscribeToEvents(t, redisClient, "ReservationExpired")
+
+	showID := createShow(t, false)
+	unpaidBookingID := bookTicketsForBookingID(t, showID, 5)
+	paidBookingID := bookTicketsForBookingID(t, showID, 5)
+
+	resp := sendBookTicketsRequest(t, map[string]any{
+		"show_id":           createShow(t, true),
+		"number_of_tickets": 2,
+		"customer_email":    "vip@example.com",
+		"vip":               true,
+	})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var vipBooking struct {
+		BookingID string `json:"booking_id"`
+	}
+	err := json.NewDecoder(resp.Body).Decode(&vipBooking)
+	require.NoError(t, err)
+
+	var taxiBookingID string
+	assert.EventuallyWithT(
+		t,
+		func(collectT *assert.CollectT) {
+			booking, err := bookingsRepository.BookingByID(context.Background(), vipBooking.BookingID)
+			if assert.NoError(collectT, err) && assert.NotNil(collectT, booking.TaxiBookingID) {
+				taxiBookingID = *booking.TaxiBookingID
+			}
+		},
+		10*time.Second,
+		100*time.Millisecond,
+	)
+
+	ticket := getTestTicket("confirmed")
+	ticket.BookingID = paidBookingID
+	sendTicketsStatus(t, entities.TicketsStatusRequest{Tickets: []entities.Ticket{ticket}})
+
+	assert.EventuallyWithT(
+		t,
+		func(collectT *assert.CollectT) {
+			booking, err := bookingsRepository.BookingByID(context.Background(), paidBookingID)
+			require.NoError(collectT, err)
+			assert.Nil(collectT, booking.ExpiresAt)
+		},
+		10*time.Second,
+		100*time.Millisecond,
+	)
+
+	vipBundle := waitForVipBundleStatus(
+		t,
+		bookVipBundle(t, createShow(t, false), uuid.NewString(), uuid.NewString()),
+		entities.VipBundleStatusFinalized,
+	)
+
+	clock.Advance(ttl + time.Second)
+
+	// bookings made for VIP bundles expire like the others
+	expiredBookingIDs := map[any]bool{}
+	assertEventPublished(t, reservationExpired, func(payload map[string]any) bool {
+		expiredBookingIDs[payload["booking_id"]] = true
+		return expiredBookingIDs[unpaidBookingID] && expiredBookingIDs[vipBundle.BookingID]
+	})
+
+	// the expired VIP reservation's Dead Nation place and taxi are canceled
+	assert.EventuallyWithT(
+		t,
+		func(collectT *assert.CollectT) {
+			assert.Contains(collectT, deadNationService.CanceledBookings(), vipBooking.BookingID)
+			assert.Contains(collectT, transportationService.CancelledTaxiBookingIDs(), taxiBookingID)
+		},
+		10*time.Second,
+		100*time.Millisecond,
+	)
+
+	// the expired booking released its tickets
+	assert.EventuallyWithT(
+		t,
+		func(collectT *assert.CollectT) {
+			_, err := bookingsRepository.BookingByID(context.Background(), unpaidBookingID)
+			assert.ErrorIs(collectT, err, entities.ErrBookingNotFound)
+		},
+		10*time.Second,
+		100*time.Millisecond,
+	)
+	resp = bookTickets(t, showID, 5)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	_, err = bookingsRepository.BookingByID(context.Background(), paidBookingID)
+	assert.NoError(t, err)
+
+	// tickets of the expired booking can't be confirmed anymore
+	expiredTicket := getTestTicket("confirmed")
+	expiredTicket.BookingID = unpaidBookingID
+	resp = postTicketsStatus(t, entities.TicketsStatusRequest{Tickets: []entities.Ticket{expiredTicket}})
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+}
+
+func bookTicketsForBookingID(t *testing.T, showID string, numberOfTickets int) string {
+	t.Helper()
+
+	resp := bookTickets(t, showID, numberOfTickets)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var body struct {
+		BookingID string `json:"booking_id"`
+	}
+	err := json.NewDecoder(resp.Body).Decode(&body)
+	require.NoError(t, err)
+
+	return body.BookingID
+}
+
+func testShowReminders(t *testing.T, notificationsService *api.NotificationsMock, reminderBefore time.Duration) {
+	showID := createShowStartingAt(t, false, time.Now().Add(reminderBefore+2*time.Second))
+
+	resp := bookTickets(t, showID, 2)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var body struct {
+		BookingID string `json:"booking_id"`
+	}
+	err := json.NewDecoder(resp.Body).Decode(&body)
+	require.NoError(t, err)
+
+	reminded := getTestTicket("confirmed")
+	reminded.BookingID = body.BookingID
+	reminded.CustomerEmail = "reminded-" + shortuuid.New() + "@example.com"
+
+	canceled := getTestTicket("confirmed")
+	canceled.BookingID = body.BookingID
+	canceled.CustomerEmail = "canceled-" + shortuuid.New() + "@example.com"
+
+	sendTicketsStatus(t, entities.TicketsStatusRequest{Tickets: []entities.Ticket{reminded, canceled}})
+	// a redelivered confirmation doesn't send the reminder twice
+	sendTicketsStatus(t, entities.TicketsStatusRequest{Tickets: []entities.Ticket{reminded}})
+
+	canceled.Status = "canceled"
+	sendTicketsStatus(t, entities.TicketsStatusRequest{Tickets: []entities.Ticket{canceled}})
+
+	subject := "Reminder: Test Show is coming up"
+	assertEmailSent(t, notificationsService, reminded, subject)
+
+	// the canceled ticket's and the duplicate reminder are due at the same time
+	assert.Never(
+		t,
+		func() bool {
+			remindersSent := map[string]int{}
+			for _, email := range notificationsService.SentEmails() {
+				if email.Subject == subject {
+					remindersSent[email.To]++
+				}
+			}
+
+			return remindersSent[reminded.CustomerEmail] > 1 || remindersSent[canceled.CustomerEmail] > 0
+		},
+		time.Second,
+		100*time.Millisecond,
+		"reminder sent twice or for the canceled ticket",
+	)
+}
+
+func testDelayedPublish(t *testing.T, dbConn *sqlx.DB, redisClient *redis.Client) {
+	ticketPrinted := subscribeToEvents(t, redisClient, "TicketPrinted")
+
+	eventBus := event.NewEventBus(
+		message.NewRedisPublisher(redisClient, watermill.NopLogger{}),
+		db.NewDelayedMessagesRepository(dbConn),
+	)
+
+	ticketID := uuid.NewString()
+	publishAt := time.Now().Add(time.Second)
+
+	err := eventBus.PublishAt(context.Background(), entities.TicketPrinted{
+		Header:   entities.NewEventHeader(),
+		TicketID: ticketID,
+		FileName: ticketID + "-ticket.html",
+	}, publishAt)
+	require.NoError(t, err)
+
+	assertEventPublished(t, ticketPrinted, func(payload map[string]any) bool {
+		return payload["ticket_id"] == ticketID
+	})
+	assert.False(t, time.Now().Before(publishAt), "delayed event published too early")
+}
+
+func testWebhooks(t *testing.T) {
+	secret := "partner-secret"
+
+	lock := sync.Mutex{}
+	var delivered []string
+	requests := 0
+	partner := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(r.Header.Get("X-Webhook-Timestamp") + "."))
+		mac.Write(body)
+		assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), r.Header.Get("X-Webhook-Signature"))
+		assert.Equal(t, "TicketBookingConfirmed", r.Header.Get("X-Webhook-Event"))
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		requests++
+		if requests == 1 {
+			// the first delivery is retried
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		delivered = append(delivered, string(body))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer partner.Close()
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+
+	otherPartnerDeliveries := 0
+	otherPartner := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		otherPartnerDeliveries++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer otherPartner.Close()
+
+	showID := createShow(t, false)
+
+	resp := postWebhook(t, partner.URL, secret, showID, "wrong-token")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp = postWebhook(t, strings.Replace(partner.URL, "http://", "http://user:password@", 1), secret, showID, adminToken)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = postWebhook(t, partner.URL, secret, "", adminToken)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	partnerWebhookID := registerWebhook(t, partner.URL, secret, showID)
+	failingWebhookID := registerWebhook(t, failing.URL, "other-secret", showID)
+	otherPartnerWebhookID := registerWebhook(t, otherPartner.URL, "other-partner-secret", createShow(t, false))
+
+	bookingID := bookTicketsForBookingID(t, showID, 2)
+	tickets := []entities.Ticket{getTestTicket("confirmed"), getTestTicket("confirmed")}
+	for i := range tickets {
+		tickets[i].BookingID = bookingID
+	}
+	sendTicketsStatus(t, entities.TicketsStatusRequest{Tickets: tickets})
+
+	assert.EventuallyWithT(
+		t,
+		func(collectT *assert.CollectT) {
+			lock.Lock()
+			defer lock.Unlock()
+
+			for _, ticket := range tickets {
+				assert.Truef(collectT, slices.ContainsFunc(delivered, func(body string) bool {
+					return strings.Contains(body, ticket.TicketID)
+				}), "ticket %s not delivered", ticket.TicketID)
+			}
+		},
+		10*time.Second,
+		100*time.Millisecond,
+	)
+
+	webhook := getWebhook(t, partnerWebhookID)
+	assert.False(t, webhook.Disabled)
+	assert.Equal(t, 0, webhook.ConsecutiveFailures)
+	assert.True(t, slices.ContainsFunc(webhook.Attempts, func(attempt entities.WebhookAttempt) bool {
+		return !attempt.Succeeded && attempt.StatusCode == http.StatusInternalServerError
+	}), "failed attempt should be recorded")
+
+	assert.EventuallyWithT(
+		t,
+		func(collectT *assert.CollectT) {
+			webhook := getWebhook(collectT, failingWebhookID)
+			assert.True(collectT, webhook.Disabled)
+			assert.GreaterOrEqual(collectT, len(webhook.Attempts), 4)
+		},
+		10*time.Second,
+		100*time.Millisecond,
+	)
+
+	// events of the other partner's show would be delivered along with the ones above
+	assert.Empty(t, getWebhook(t, otherPartnerWebhookID).Attempts)
+	lock.Lock()
+	assert.Equal(t, 0, otherPartnerDeliveries)
+	lock.Unlock()
+}
+
+const adminToken = "test-admin-token"
+
+func registerWebhook(t *testing.T, url string, secret string, showID string) string {
+	t.Helper()
+
+	resp := postWebhook(t, url, secret, showID, adminToken)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var body struct {
+		WebhookID string `json:"webhook_id"`
+	}
+	err := json.NewDecoder(resp.Body).Decode(&body)
+	require.NoError(t, err)
+
+	return body.WebhookID
+}
+
+func postWebhook(t *testing.T, url string, secret string, showID string, token string) *http.Response {
+	t.Helper()
+
+	request := map[string]any{
+		"url":         url,
+		"event_types": []string{"TicketBookingConfirmed"},
+		"secret":      secret,
+	}
+	if showID != "" {
+		request["show_ids"] = []string{showID}
+	}
+
+	payload, err := json.Marshal(request)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, "http://localhost:8080/webhooks", bytes.NewBuffer(payload))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	return resp
+}
+
+type webhookResponse struct {
+	Disabled            bool                      `json:"disabled"`
+	ConsecutiveFailures int                       `json:"consecutive_failures"`
+	Attempts            []entities.WebhookAttempt `json:"attempts"`
+}
+
+func getWebhook(t assert.TestingT, webhookID string) webhookResponse {
+	resp, err := adminRequest(http.MethodGet, "http://localhost:8080/webhooks/"+webhookID, nil)
+	if !assert.NoError(t, err) {
+		return webhookResponse{}
+	}
+	defer resp.Body.Close()
+
+	var webhook webhookResponse
+	if assert.Equal(t, http.StatusOK, resp.StatusCode) {
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&webhook))
+	}
+
+	return webhook
+}
+
+func testTicketsStream(t *testing.T) {
+	showID := createShow(t, false)
+	customerEmail := "stream-" + shortuuid.New() + "@example.com"
+
+	resp := sendBookTicketsRequest(t, map[string]any{
+		"show_id":           showID,
+		"number_of_tickets": 1,
+		"customer_email":    customerEmail,
+	})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var body struct {
+		BookingID string `json:"booking_id"`
+	}
+	err := json.NewDecoder(resp.Body).Decode(&body)
+	require.NoError(t, err)
+
+	byShow := subscribeToTicketsStream(t, "show_id="+showID, "")
+
+	ticket := getTestTicket("confirmed")
+	ticket.BookingID = body.BookingID
+	ticket.CustomerEmail = customerEmail
+	// not of the show, so it's filtered out
+	otherTicket := getTestTicket("confirmed")
+	sendTicketsStatus(t, entities.TicketsStatusRequest{Tickets: []entities.Ticket{otherTicket, ticket}})
+
+	confirmed := waitForTicketStatus(t, byShow, showID, ticket.TicketID, entities.TicketStatusConfirmed)
+	assert.Equal(t, customerEmail, confirmed.Change.CustomerEmail)
+	waitForTicketStatus(t, byShow, showID, ticket.TicketID, entities.TicketStatusReceipted)
+
+	// resuming after the confirmation replays the changes that followed it
+	resumed := subscribeToTicketsStream(t, "customer_email="+customerEmail, confirmed.ID)
+	waitForTicketStatus(t, resumed, showID, ticket.TicketID, entities.TicketStatusReceipted)
+
+	ticket.Status = "canceled"
+	sendTicketsStatus(t, entities.TicketsStatusRequest{Tickets: []entities.Ticket{ticket}})
+
+	waitForTicketStatus(t, resumed, showID, ticket.TicketID, entities.TicketStatusCanceled)
+	waitForTicketStatus(t, resumed, showID, ticket.TicketID, entities.TicketStatusRefunded)
+}
+
+type ticketsStreamEvent struct {
+	ID     string
+	Change entities.TicketStatusChanged
+}
+
+func subscribeToTicketsStream(t *testing.T, query string, lastEventID string) <-chan ticketsStreamEvent {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost:8080/tickets/stream?"+query, nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	events := make(chan ticketsStreamEvent)
+	go func() {
+		defer resp.Body.Close()
+		defer close(events)
+
+		var event ticketsStreamEvent
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "id: "):
+				event.ID = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "data: "):
+				if json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event.Change) != nil {
+					return
+				}
+			case line == "" && event.ID != "":
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+				event = ticketsStreamEvent{}
+			}
+		}
+	}()
+
+	return events
+}
+
+func waitForTicketStatus(
+	t *testing.T,
+	events <-chan ticketsStreamEvent,
+	showID string,
+	ticketID string,
+	status string,
+) ticketsStreamEvent {
+	t.Helper()
+
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case event, ok := <-events:
+			require.True(t, ok, "tickets stream closed")
+			assert.Equal(t, showID, event.Change.ShowID, "only changes of the show should be streamed")
+
+			if event.Change.TicketID == ticketID && event.Change.Status == status {
+				return event
+			}
+		case <-timeout:
+			require.Failf(t, "ticket status not streamed", "%s of ticket %s", status, ticketID)
+		}
+	}
+}
+
+func testOpsBookings(t *testing.T) {
+	showID := createShow(t, false)
+
+	resp := bookTickets(t, showID, 1)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var body struct {
+		BookingID string `json:"booking_id"`
+	}
+	err := json.NewDecoder(resp.Body).Decode(&body)
+	require.NoError(t, err)
+
+	ticket := getTestTicket("confirmed")
+	ticket.BookingID = body.BookingID
+	sendTicketsStatus(t, entities.TicketsStatusRequest{Tickets: []entities.Ticket{ticket}})
+
+	ticket.Status = "canceled"
+	sendTicketsStatus(t, entities.TicketsStatusRequest{Tickets: []entities.Ticket{ticket}})
+
+	var opsBooking entities.OpsBooking
+	assert.EventuallyWithT(
+		t,
+		func(collectT *assert.CollectT) {
+			opsBooking = getOpsBooking(collectT, body.BookingID)
+
+			assert.NotNil(collectT, opsBooking.BookedAt)
+			assert.Equal(collectT, showID, opsBooking.ShowID)
+
+			opsTicket := opsBooking.Tickets[ticket.TicketID]
+			assert.Equal(collectT, ticket.Price, opsTicket.Price)
+			assert.NotNil(collectT, opsTicket.ConfirmedAt)
+			assert.NotNil(collectT, opsTicket.PrintedAt)
+			assert.NotNil(collectT, opsTicket.CanceledAt)
+			assert.NotNil(collectT, opsTicket.RefundedAt)
+			assert.NotNil(collectT, opsTicket.ReceiptIssuedAt)
+			assert.NotEmpty(collectT, opsTicket.ReceiptNumber)
+		},
+		10*time.Second,
+		100*time.Millisecond,
+	)
+
+	resp, err = adminRequest(http.MethodPost, "http://localhost:8080/ops/projections/OpsBookings/replay", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var replay struct {
+		ReplayedEvents int `json:"replayed_events"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&replay)
+	require.NoError(t, err)
+	assert.Positive(t, replay.ReplayedEvents)
+
+	assert.Equal(t, opsBooking, getOpsBooking(t, body.BookingID), "replaying should not change the read model")
+
+	receiptIssueDate := opsBooking.Tickets[ticket.TicketID].ReceiptIssuedAt.Format(time.DateOnly)
+	assert.Contains(t, getOpsBookingIDs(t, receiptIssueDate), body.BookingID)
+
+	otherDate := opsBooking.Tickets[ticket.TicketID].ReceiptIssuedAt.AddDate(0, 0, -1).Format(time.DateOnly)
+	assert.NotContains(t, getOpsBookingIDs(t, otherDate), body.BookingID)
+
+	resp, err = adminRequest(http.MethodGet, "http://localhost:8080/ops/bookings?receipt_issue_date=tomorrow", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+// adminRequest sends a request with the admin token, which the admin endpoints require.
+func adminRequest(method string, url string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+
+	return http.DefaultClient.Do(req)
+}
+
+func getOpsBookingIDs(t *testing.T, receiptIssueDate string) []string {
+	t.Helper()
+
+	var bookingIDs []string
+	after := ""
+	for {
+		resp, err := adminRequest(http.MethodGet, "http://localhost:8080/ops/bookings?limit=10&receipt_issue_date="+receiptIssueDate+"&after="+after, nil)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var opsBookings []entities.OpsBooking
+		err = json.NewDecoder(resp.Body).Decode(&opsBookings)
+		resp.Body.Close()
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(opsBookings), 10)
+
+		for _, opsBooking := range opsBookings {
+			bookingIDs = append(bookingIDs, opsBooking.BookingID)
+		}
+
+		if len(opsBookings) < 10 {
+			return bookingIDs
+		}
+		after = opsBookings[len(opsBookings)-1].BookingID
+	}
+}
+
+func getOpsBooking(t assert.TestingT, bookingID string) entities.OpsBooking {
+	resp, err := adminRequest(http.MethodGet, "http://localhost:8080/ops/bookings/"+bookingID, nil)
+	if !assert.NoError(t, err) {
+		return entities.OpsBooking{}
+	}
+	defer resp.Body.Close()
+
+	var opsBooking entities.OpsBooking
+	if assert.Equal(t, http.StatusOK, resp.StatusCode) {
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&opsBooking))
+	}
+
+	return opsBooking
+}
+
+func testReplayUnknownProjection(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "http://localhost:8080/ops/projections/OpsBookings/replay", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer wrong-token")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, "replaying requires the admin token")
+
+	resp, err = adminRequest(http.MethodPost, "http://localhost:8080/ops/projections/unknown/replay", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func testEventStore(t *testing.T) {
+	ticket := getTestTicket("confirmed")
+	sendTicketsStatus(t, entities.TicketsStatusRequest{Tickets: []entities.Ticket{ticket}})
+
+	ticket.Status = "canceled"
+	sendTicketsStatus(t, entities.TicketsStatusRequest{Tickets: []entities.Ticket{ticket}})
+
+	assert.EventuallyWithT(
+		t,
+		func(collectT *assert.CollectT) {
+			resp, err := adminRequest(http.MethodGet, "http://localhost:8080/ops/events?ticket_id="+ticket.TicketID, nil)
+			if !assert.NoError(collectT, err) {
+				return
+			}
+			defer resp.Body.Close()
+
+			var events []entities.StoredEvent
+			if !assert.NoError(collectT, json.NewDecoder(resp.Body).Decode(&events)) {
+				return
+			}
+
+			names := make([]string, 0, len(events))
+			for _, event := range events {
+				names = append(names, event.EventName)
+				assert.NotEmpty(collectT, event.CorrelationID)
+				assert.Contains(collectT, string(event.Payload), ticket.TicketID)
+			}
+			assert.Contains(collectT, names, "TicketBookingConfirmed")
+			assert.Contains(collectT, names, "TicketBookingCanceled")
+		},
+		10*time.Second,
+		100*time.Millisecond,
+	)
+}
+
+func testVipBundle(t *testing.T, transportationService *api.TransportationMock) {
+	showID := createShow(t, false)
+
+	vipBundleID := bookVipBundle(t, showID, uuid.NewString(), uuid.NewString())
+
+	vipBundle := waitForVipBundleStatus(t, vipBundleID, entities.VipBundleStatusFinalized)
+
+	assert.Len(t, vipBundle.InboundFlightTicketIDs, 2)
+	assert.Len(t, vipBundle.ReturnFlightTicketIDs, 2)
+	assert.Contains(t, transportationService.TaxiBookings, vipBundle.TaxiBookingID)
+}
+
+func testVipBundleCompensated(t *testing.T, transportationService *api.TransportationMock) {
+	showID := createShow(t, false)
+
+	returnFlightID := uuid.NewString()
+	transportationService.RejectFlight(returnFlightID)
+
+	vipBundleID := bookVipBundle(t, showID, uuid.NewString(), returnFlightID)
+
+	vipBundle := waitForVipBundleStatus(t, vipBundleID, entities.VipBundleStatusFailed)
+
+	assert.NotEmpty(t, vipBundle.FailureReason)
+	assert.True(t, vipBundle.InboundFlightCanceled)
+	assert.True(t, vipBundle.BookingCanceled)
+	for _, ticketID := range vipBundle.InboundFlightTicketIDs {
+		assert.Contains(t, transportationService.CancelledFlightTickets, ticketID)
+	}
+
+	// the canceled booking released the show's tickets
+	resp := bookTickets(t, showID, 10)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+}
+
+func bookVipBundle(t *testing.T, showID string, inboundFlightID string, returnFlightID string) string {
+	t.Helper()
+
+	payload, err := json.Marshal(map[string]any{
+		"show_id":           showID,
+		"number_of_tickets": 2,
+		"customer_email":    "vip@example.com",
+		"passengers":        []string{"John Doe", "Jane Doe"},
+		"inbound_flight_id": inboundFlightID,
+		"return_flight_id":  returnFlightID,
+	})
+	require.NoError(t, err)
+
+	resp, err := http.Post("http://localhost:8080/book-vip-bundle", "application/json", bytes.NewBuffer(payload))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var body struct {
+		VipBundleID string `json:"vip_bundle_id"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	require.NoError(t, err)
+
+	return body.VipBundleID
+}
+
+func waitForVipBundleStatus(t *testing.T, vipBundleID string, status entities.VipBundleStatus) entities.VipBundle {
+	t.Helper()
+
+	var vipBundle entities.VipBundle
+	assert.EventuallyWithT(
+		t,
+		func(collectT *assert.CollectT) {
+			resp, err := adminRequest(http.MethodGet, "http://localhost:8080/ops/vip-bundles/"+vipBundleID, nil)
+			if !assert.NoError(collectT, err) {
+				return
+			}
+			defer resp.Body.Close()
+
+			if !assert.Equal(collectT, http.StatusOK, resp.StatusCode) {
+				return
+			}
+
+			vipBundle = entities.VipBundle{}
+			err = json.NewDecoder(resp.Body).Decode(&vipBundle)
+			if !assert.NoError(collectT, err) {
+				return
+			}
+
+			assert.Equal(collectT, status, vipBundle.Status)
+		},
+		10*time.Second,
+		100*time.Millisecond,
+	)
+
+	return vipBundle
+}
+
+func testVIPTaxi(t *testing.T, transportationService *api.TransportationMock) {
+	showID := createShow(t, false)
+
+	resp := sendBookTicketsRequest(t, map[string]any{
+		"show_id":           showID,
+		"number_of_tickets": 2,
+		"customer_email":    "vip@example.com",
+		"vip":               true,
+	})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var body struct {
+		BookingID string `json:"booking_id"`
+	}
+	err := json.NewDecoder(resp.Body).Decode(&body)
+	require.NoError(t, err)
+
+	var taxiBookingID string
+	assert.EventuallyWithT(
+		t,
+		func(collectT *assert.CollectT) {
+			for id, taxiBooking := range transportationService.TaxiBookings {
+				if taxiBooking.ReferenceID == body.BookingID {
+					taxiBookingID = id
+					assert.Equal(collectT, 2, taxiBooking.NumberOfPassengers)
+				}
+			}
+			assert.NotEmptyf(collectT, taxiBookingID, "taxi for booking %s not booked", body.BookingID)
+		},
+		10*time.Second,
+		100*time.Millisecond,
+	)
+
+	ticket := getTestTicket("canceled")
+	ticket.BookingID = body.BookingID
+
+	sendTicketsStatus(t, entities.TicketsStatusRequest{Tickets: []entities.Ticket{ticket}})
+
+	assert.EventuallyWithT(
+		t,
+		func(collectT *assert.CollectT) {
+			assert.Contains(collectT, transportationService.CancelledTaxiBookings, taxiBookingID)
+		},
+		10*time.Second,
+		100*time.Millisecond,
+	)
+}
+
+func testNotifications(t *testing.T, notificationsService *api.NotificationsMock) {
+	confirmed := getTestTicket("confirmed")
+	confirmed.Locale = "lt"
+
+	canceled := getTestTicket("canceled")
+	// no template in this locale, the default one is used
+	canceled.Locale = "de"
+
+	sendTicketsStatus(t, entities.TicketsStatusRequest{Tickets: []entities.Ticket{confirmed, canceled}})
+
+	assertEmailSent(t, notificationsService, confirmed, "Bilietas "+confirmed.TicketID+" patvirtintas")
+	assertEmailSent(t, notificationsService, canceled, "Your ticket "+canceled.TicketID+" was canceled")
+}
+
+func assertEmailSent(t *testing.T, notificationsService *api.NotificationsMock, ticket entities.Ticket, subject string) {
+	assert.EventuallyWithT(
+		t,
+		func(collectT *assert.CollectT) {
+			var found bool
+			for _, email := range notificationsService.SentEmails() {
+				if email.Subject == subject {
+					found = true
+					assert.Equal(collectT, ticket.CustomerEmail, email.To)
+					assert.Contains(collectT, email.Body, ticket.TicketID)
+				}
+			}
+			assert.Truef(collectT, found, "email %q not sent", subject)
+		},
+		10*time.Second,
+		100*time.Millisecond,
+	)
+}
+
+func testPrintTicket(t *testing.T, redisClient *redis.Client, filesService *api.FilesMock) {
+	showID := createShow(t, false)
+
+	resp := bookTickets(t, showID, 1)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var body struct {
+		BookingID string `json:"booking_id"`
+	}
+	err := json.NewDecoder(resp.Body).Decode(&body)
+	require.NoError(t, err)
+
+	ticket := getTestTicket("confirmed")
+	ticket.BookingID = body.BookingID
+
+	ticketPrinted := subscribeToEvents(t, redisClient, "TicketPrinted")
+
+	sendTicketsStatus(t, entities.TicketsStatusRequest{Tickets: []entities.Ticket{ticket}})
+
+	fileName := ticket.TicketID + "-ticket.html"
+
+	assert.EventuallyWithT(
+		t,
+		func(collectT *assert.CollectT) {
+			content, ok := filesService.Files[fileName]
+			if !assert.Truef(collectT, ok, "ticket file %s not uploaded", fileName) {
+				return
+			}
+
+			assert.Contains(collectT, content, ticket.TicketID)
+			assert.Contains(collectT, content, "Test Show")
+			assert.Contains(collectT, content, ticket.Price.Amount)
+		},
+		10*time.Second,
+		100*time.Millisecond,
+	)
+
+	assertEventPublished(t, ticketPrinted, func(payload map[string]any) bool {
+		return payload["ticket_id"] == ticket.TicketID && payload["file_name"] == fileName
+	})
+}
+
+func subscribeToEvents(t *testing.T, redisClient *redis.Client, topic string) <-chan *watermillMessage.Message {
+	t.Helper()
+
+	consumerGroup := "test-" + uuid.NewString()
+
+	sub, err := redisstream.NewSubscriber(redisstream.SubscriberConfig{
+		Client:        redisClient,
+		ConsumerGroup: consumerGroup,
+	}, watermill.NopLogger{})
+	require.NoError(t, err)
+
+	// cleanups run in reverse order, so the group is removed after the subscription is canceled;
+	// the shared client is closed by the service by then
+	t.Cleanup(func() {
+		client := redis.NewClient(redisClient.Options())
+		defer client.Close()
+
+		err := client.XGroupDestroy(context.Background(), topic, consumerGroup).Err()
+		assert.NoError(t, err)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
 
-	spreadsheetsService := &api.SpreadsheetsMock{}
-	receiptsService := &api.ReceiptsMock{}
+	messages, err := sub.Subscribe(ctx, topic)
+	require.NoError(t, err)
 
-	go func() {
-		svc := service.New(
-			redisClient,
-			spreadsheetsService,
-			receiptsService,
-		)
-		assert.NoError(t, svc.Run(ctx))
-	}()
+	return messages
+}
 
-	waitForHttpServer(t)
-	testTicketsStatusConfirmed(t, receiptsService, spreadsheetsService)
-	testTicketsStatusCanceled(t, spreadsheetsService)
+func assertEventPublished(t *testing.T, messages <-chan *watermillMessage.Message, match func(payload map[string]any) bool) {
+	t.Helper()
+
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case msg := <-messages:
+			msg.Ack()
+
+			var payload map[string]any
+			err := json.Unmarshal(msg.Payload, &payload)
+			require.NoError(t, err)
+
+			if match(payload) {
+				return
+			}
+		case <-timeout:
+			t.Fatal("expected event was not published")
+		}
+	}
+}
+
+func testBookTicketsInDeadNation(t *testing.T, deadNationService *api.DeadNationMock) {
+	showID := createShow(t, true)
+
+	// the partner is down for the first attempts, the booking should still reach it with retries
+	deadNationService.FailNextCalls(3)
+
+	resp := bookTickets(t, showID, 2)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var body struct {
+		BookingID string `json:"booking_id"`
+	}
+	err := json.NewDecoder(resp.Body).Decode(&body)
+	require.NoError(t, err)
+
+	assert.EventuallyWithT(
+		t,
+		func(collectT *assert.CollectT) {
+			var found bool
+			for _, booking := range deadNationService.Bookings {
+				if booking.BookingID == body.BookingID {
+					found = true
+					assert.Equal(collectT, 2, booking.NumberOfTickets)
+					assert.Equal(collectT, "email@example.com", booking.CustomerEmail)
+				}
+			}
+			assert.Truef(collectT, found, "booking %s not sent to Dead Nation", body.BookingID)
+		},
+		10*time.Second,
+		100*time.Millisecond,
+	)
+
+	// bookings are handled in order, so the ones made earlier for our own shows are handled by now
+	assert.Len(t, deadNationService.Bookings, 1, "only bookings of external shows should be sent to Dead Nation")
+}
+
+func testBookTickets(t *testing.T) {
+	showID := createShow(t, false)
+
+	resp := bookTickets(t, showID, 7)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var body struct {
+		BookingID string `json:"booking_id"`
+	}
+	err := json.NewDecoder(resp.Body).Decode(&body)
+	require.NoError(t, err)
+	assert.NotEmpty(t, body.BookingID)
+
+	resp = bookTickets(t, showID, 4)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "booking over the show's capacity should be rejected")
+
+	resp = bookTickets(t, showID, 3)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp = bookTickets(t, "not-a-show-id", 1)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = bookTickets(t, uuid.NewString(), 1)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func bookTickets(t *testing.T, showID string, numberOfTickets int) *http.Response {
+	t.Helper()
+
+	return sendBookTicketsRequest(t, map[string]any{
+		"show_id":           showID,
+		"number_of_tickets": numberOfTickets,
+		"customer_email":    "email@example.com",
+	})
+}
+
+func sendBookTicketsRequest(t *testing.T, request map[string]any) *http.Response {
+	t.Helper()
+
+	payload, err := json.Marshal(request)
+	require.NoError(t, err)
+
+	resp, err := http.Post("http://localhost:8080/book-tickets", "application/json", bytes.NewBuffer(payload))
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+
+	return resp
+}
+
+func testShows(t *testing.T) {
+	showID := createShow(t, false)
+
+	resp, err := http.Get("http://localhost:8080/shows")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var shows []entities.Show
+	err = json.NewDecoder(resp.Body).Decode(&shows)
+	require.NoError(t, err)
+
+	var found bool
+	for _, show := range shows {
+		if show.ShowID == showID {
+			found = true
+			assert.Equal(t, "Test Show", show.Title)
+			assert.Equal(t, 10, show.NumberOfTickets)
+		}
+	}
+	assert.Truef(t, found, "show %s not found", showID)
+}
+
+func createShow(t *testing.T, externalProvider bool) string {
+	t.Helper()
+
+	return createShowStartingAt(t, externalProvider, time.Now().Add(7*24*time.Hour))
+}
+
+func createShowStartingAt(t *testing.T, externalProvider bool, startTime time.Time) string {
+	t.Helper()
+
+	request := map[string]any{
+		"title":             "Test Show",
+		"venue":             "Test Venue",
+		"start_time":        startTime.UTC(),
+		"number_of_tickets": 10,
+		"external_provider": externalProvider,
+	}
+	if externalProvider {
+		request["dead_nation_id"] = uuid.NewString()
+	}
+
+	return postShow(t, request)
+}
+
+func postShow(t *testing.T, request map[string]any) string {
+	t.Helper()
+
+	payload, err := json.Marshal(request)
+	require.NoError(t, err)
+
+	resp, err := http.Post("http://localhost:8080/shows", "application/json", bytes.NewBuffer(payload))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var body struct {
+		ShowID string `json:"show_id"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	require.NoError(t, err)
+
+	return body.ShowID
+}
+
+func testRefundPolicy(
+	t *testing.T,
+	spreadsheetsService *api.SpreadsheetsMock,
+	notificationsService *api.NotificationsMock,
+	paymentsService *api.PaymentsMock,
+) {
+	refundPolicy := []map[string]any{
+		{"hours_before_show": 7 * 24, "percent": 100},
+		{"hours_before_show": 48, "percent": 50},
+	}
+
+	for _, tc := range []struct {
+		name      string
+		startTime time.Time
+		refund    string
+		// payments can only be refunded in full
+		paymentRefunded bool
+	}{
+		{name: "full", startTime: time.Now().Add(8 * 24 * time.Hour), refund: "50.00", paymentRefunded: true},
+		{name: "partial", startTime: time.Now().Add(72 * time.Hour), refund: "25.00"},
+		{name: "none", startTime: time.Now().Add(24 * time.Hour), refund: "0.00"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			showID := postShow(t, map[string]any{
+				"title":             "Refund Policy Show",
+				"venue":             "Test Venue",
+				"start_time":        tc.startTime.UTC(),
+				"number_of_tickets": 10,
+				"refund_policy":     refundPolicy,
+			})
+
+			ticket := getTestTicket("canceled")
+			ticket.BookingID = bookTicketsForBookingID(t, showID, 1)
+
+			sendTicketsStatus(t, entities.TicketsStatusRequest{Tickets: []entities.Ticket{ticket}})
+
+			assert.EventuallyWithT(
+				t,
+				func(collectT *assert.CollectT) {
+					assert.Contains(collectT, spreadsheetsService.Rows("tickets-to-refund"), []string{
+						ticket.TicketID, ticket.CustomerEmail, tc.refund, ticket.Price.Currency,
+					})
+				},
+				10*time.Second,
+				100*time.Millisecond,
+			)
+
+			assert.EventuallyWithT(
+				t,
+				func(collectT *assert.CollectT) {
+					assert.True(collectT, slices.ContainsFunc(notificationsService.SentEmails(), func(email entities.Email) bool {
+						return strings.Contains(email.Body, ticket.TicketID) &&
+							strings.Contains(email.Body, "We will refund "+tc.refund+" "+ticket.Price.Currency)
+					}), "cancellation email with the refund not sent")
+				},
+				10*time.Second,
+				100*time.Millisecond,
+			)
+
+			assert.EventuallyWithT(
+				t,
+				func(collectT *assert.CollectT) {
+					opsTicket := getOpsBooking(collectT, ticket.BookingID).Tickets[ticket.TicketID]
+					assert.NotNil(collectT, opsTicket.CanceledAt)
+					if tc.refund != "0.00" {
+						assert.NotNil(collectT, opsTicket.RefundedAt)
+					}
+				},
+				10*time.Second,
+				100*time.Millisecond,
+			)
+			if tc.refund == "0.00" {
+				assert.Never(t, func() bool {
+					return getOpsBooking(t, ticket.BookingID).Tickets[ticket.TicketID].RefundedAt != nil
+				}, time.Second, 100*time.Millisecond, "ticket refunded nothing should not be marked as refunded")
+			}
+
+			refunded := func() bool {
+				return slices.ContainsFunc(paymentsService.Refunds(), func(refund entities.PaymentRefund) bool {
+					return refund.TicketID == ticket.TicketID
+				})
+			}
+			if !tc.paymentRefunded {
+				assert.Never(t, refunded, time.Second, 100*time.Millisecond, "payment should not be refunded")
+				return
+			}
+			assert.Eventually(t, refunded, 10*time.Second, 100*time.Millisecond, "payment not refunded")
+		})
+	}
+
+	resp, err := http.Post("http://localhost:8080/shows", "application/json", strings.NewReader(`{
+		"title": "Refund Policy Show",
+		"venue": "Test Venue",
+		"start_time": "2030-01-01T20:00:00Z",
+		"number_of_tickets": 10,
+		"refund_policy": [{"hours_before_show": 48, "percent": 150}]
+	}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func testPromoCodes(t *testing.T, receiptsService *api.ReceiptsMock) {
+	code := "SPRING-" + shortuuid.New()
+
+	resp := postPromoCode(t, map[string]any{
+		"code":                  code,
+		"percent_off":           20,
+		"max_uses":              2,
+		"max_uses_per_customer": 1,
+	})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp = postPromoCode(t, map[string]any{"code": code, "percent_off": 10})
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	resp = postPromoCode(t, map[string]any{"code": "NO-DISCOUNT-" + shortuuid.New()})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	expiredCode := "EXPIRED-" + shortuuid.New()
+	resp = postPromoCode(t, map[string]any{
+		"code":        expiredCode,
+		"amount_off":  map[string]any{"amount": "10", "currency": "USD"},
+		"valid_until": time.Now().Add(-time.Hour).UTC(),
+	})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	euroCode := "EURO-" + shortuuid.New()
+	resp = postPromoCode(t, map[string]any{
+		"code":       euroCode,
+		"amount_off": map[string]any{"amount": "10", "currency": "EUR"},
+	})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	showID := postShow(t, map[string]any{
+		"title":             "Test Show",
+		"venue":             "Test Venue",
+		"start_time":        time.Now().Add(7 * 24 * time.Hour).UTC(),
+		"number_of_tickets": 10,
+		"ticket_price":      map[string]any{"amount": "50", "currency": "USD"},
+	})
+
+	bookWithPromoCode := func(promoCode string, customerEmail string) *http.Response {
+		return sendBookTicketsRequest(t, map[string]any{
+			"show_id":           showID,
+			"number_of_tickets": 1,
+			"customer_email":    customerEmail,
+			"promo_code":        promoCode,
+		})
+	}
+
+	resp = bookWithPromoCode(expiredCode, "first@example.com")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "expired code should be rejected")
+
+	resp = bookWithPromoCode(euroCode, "first@example.com")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "code in another currency should be rejected")
+
+	resp = sendBookTicketsRequest(t, map[string]any{
+		"show_id":           createShow(t, false),
+		"number_of_tickets": 1,
+		"customer_email":    "first@example.com",
+		"promo_code":        code,
+	})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "show without a ticket price should be rejected")
+
+	resp = bookWithPromoCode(code, "first@example.com")
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var body struct {
+		BookingID string `json:"booking_id"`
+	}
+	err := json.NewDecoder(resp.Body).Decode(&body)
+	require.NoError(t, err)
+
+	resp = bookWithPromoCode(code, "first@example.com")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "per-customer cap should be enforced")
+
+	resp = bookWithPromoCode(code, "second@example.com")
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp = bookWithPromoCode(code, "third@example.com")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "usage limit should be enforced")
+
+	getResp, err := adminRequest(http.MethodGet, "http://localhost:8080/promo-codes/"+code, nil)
+	require.NoError(t, err)
+	defer getResp.Body.Close()
+	require.Equal(t, http.StatusOK, getResp.StatusCode)
+
+	var promoCode entities.PromoCode
+	err = json.NewDecoder(getResp.Body).Decode(&promoCode)
+	require.NoError(t, err)
+	assert.Equal(t, 2, promoCode.Uses)
+
+	ticket := getTestTicket("confirmed")
+	ticket.BookingID = body.BookingID
+	sendTicketsStatus(t, entities.TicketsStatusRequest{Tickets: []entities.Ticket{ticket}})
+
+	assert.EventuallyWithT(
+		t,
+		func(collectT *assert.CollectT) {
+			assert.Contains(collectT, receiptsService.IssuedReceipts(), entities.IssueReceiptRequest{
+				TicketID: ticket.TicketID,
+				Price:    entities.Money{Amount: "40.00", Currency: "USD"},
+			})
+		},
+		10*time.Second,
+		100*time.Millisecond,
+	)
+}
+
+func postPromoCode(t *testing.T, request map[string]any) *http.Response {
+	t.Helper()
+
+	payload, err := json.Marshal(request)
+	require.NoError(t, err)
+
+	resp, err := adminRequest(http.MethodPost, "http://localhost:8080/promo-codes", bytes.NewBuffer(payload))
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+
+	return resp
+}
+
+func testHealthReady(t *testing.T) {
+	resp, err := http.Get("http://localhost:8080/health/ready")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Status string `json:"status"`
+		Checks map[string]struct {
+			Status string `json:"status"`
+		} `json:"checks"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	require.NoError(t, err)
+
+	assert.Equal(t, "ok", body.Status)
+	assert.Equal(t, "ok", body.Checks["redis"].Status)
+	assert.Equal(t, "ok", body.Checks["watermill_router"].Status)
 }
 
 func testTicketsStatusCanceled(t *testing.T, spreadsheetsService *api.SpreadsheetsMock) {
@@ -67,7 +1761,7 @@ func assertReceiptForTicketIssued(t *testing.T, receiptsService *api.ReceiptsMoc
 	assert.EventuallyWithT(
 		t,
 		func(collectT *assert.CollectT) {
-			issuedReceipts := len(receiptsService.IssuedReceipts)
+			issuedReceipts := len(receiptsService.IssuedReceipts())
 			t.Log("issued receipts", issuedReceipts)
 
 			assert.Greater(collectT, issuedReceipts, 0, "no receipts issued")
@@ -78,7 +1772,7 @@ func assertReceiptForTicketIssued(t *testing.T, receiptsService *api.ReceiptsMoc
 
 	var receipt entities.IssueReceiptRequest
 	var ok bool
-	for _, issuedReceipt := range receiptsService.IssuedReceipts {
+	for _, issuedReceipt := range receiptsService.IssuedReceipts() {
 		if issuedReceipt.TicketID != ticket.TicketID {
 			continue
 		}
@@ -97,8 +1791,9 @@ func assertRowsToSpreadsheetForTicketAppended(t *testing.T, spreadsheetsService
 	assert.EventuallyWithT(
 		t,
 		func(collectT *assert.CollectT) {
-			rows, ok := spreadsheetsService.Rows[sheetName]
-			if !assert.True(t, ok, "sheet %s not found") {
+			rows := spreadsheetsService.Rows(sheetName)
+			if !assert.NotEmpty(collectT, rows, "sheet %s not found", sheetName) {
+				return
 			}
 
 			var allValues []string
@@ -109,7 +1804,7 @@ func assertRowsToSpreadsheetForTicketAppended(t *testing.T, spreadsheetsService
 				}
 			}
 
-			assert.Contains(t, allValues, ticket.TicketID, "ticket %s not found in sheet %s", ticket.TicketID, sheetName)
+			assert.Contains(collectT, allValues, ticket.TicketID, "ticket %s not found in sheet %s", ticket.TicketID, sheetName)
 		},
 		10*time.Second,
 		100*time.Millisecond,
@@ -119,6 +1814,13 @@ func assertRowsToSpreadsheetForTicketAppended(t *testing.T, spreadsheetsService
 func sendTicketsStatus(t *testing.T, req entities.TicketsStatusRequest) {
 	t.Helper()
 
+	resp := postTicketsStatus(t, req)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func postTicketsStatus(t *testing.T, req entities.TicketsStatusRequest) *http.Response {
+	t.Helper()
+
 	payload, err := json.Marshal(req)
 	require.NoError(t, err)
 
@@ -141,7 +1843,9 @@ func sendTicketsStatus(t *testing.T, req entities.TicketsStatusRequest) {
 
 	resp, err := http.DefaultClient.Do(httpReq)
 	require.NoError(t, err)
-	require.Equal(t, http.StatusOK, resp.StatusCode)
+	t.Cleanup(func() { resp.Body.Close() })
+
+	return resp
 }
 
 func getTestTicket(status string) entities.Ticket {